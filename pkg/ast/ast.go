@@ -26,6 +26,7 @@ type Expression interface {
 
 // TranslationUnit - Root node
 type TranslationUnit struct {
+	Globals    []DeclarationStatement
 	Prototypes []Prototype
 	Functions  []FunctionLiteral
 }
@@ -43,6 +44,10 @@ func (tu *TranslationUnit) TokenLiteral() string {
 func (tu *TranslationUnit) String() string {
 	var out bytes.Buffer
 
+	for _, g := range tu.Globals {
+		out.WriteString(g.String())
+	}
+
 	for _, p := range tu.Prototypes {
 		out.WriteString(p.String())
 	}
@@ -76,6 +81,82 @@ func (oe *InfixExpression) String() string {
 	return out.String()
 }
 
+// GroupedExpression - Parenthesized node e.g. (a + b)
+type GroupedExpression struct {
+	Token      token.Token // The '(' token
+	Expression Expression
+}
+
+func (ge *GroupedExpression) expressionNode()      {}
+func (ge *GroupedExpression) TokenLiteral() string { return ge.Token.Literal }
+func (ge *GroupedExpression) String() string {
+	return "(" + ge.Expression.String() + ")"
+}
+
+// PrefixExpression - Prefix node e.g. !x, -x, ~x
+type PrefixExpression struct {
+	Token    token.Token // The prefix token, e.g. -
+	Operator string
+	Right    Expression
+}
+
+func (pe *PrefixExpression) expressionNode()      {}
+func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(pe.Operator)
+	out.WriteString(pe.Right.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// PostfixExpression - Postfix node e.g. x++
+type PostfixExpression struct {
+	Token    token.Token // The postfix token, e.g. ++
+	Left     Expression
+	Operator string
+}
+
+func (pe *PostfixExpression) expressionNode()      {}
+func (pe *PostfixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PostfixExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(pe.Left.String())
+	out.WriteString(pe.Operator)
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// ConditionalExpression - Ternary node cond ? a : b
+type ConditionalExpression struct {
+	Token       token.Token // The '?' token
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+func (ce *ConditionalExpression) expressionNode()      {}
+func (ce *ConditionalExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *ConditionalExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ce.Condition.String())
+	out.WriteString(" ? ")
+	out.WriteString(ce.Consequence.String())
+	out.WriteString(" : ")
+	out.WriteString(ce.Alternative.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
 // CallExpression - call expression node
 type CallExpression struct {
 	Token     token.Token // The '(' token
@@ -104,6 +185,51 @@ func (ce *CallExpression) GetCallee() string {
 	return ce.Function.String()
 }
 
+// IndexExpression - Array subscript e.g. a[i]
+type IndexExpression struct {
+	Token token.Token // The '[' token
+	Left  Expression  // the array or pointer
+	Index Expression
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString("[")
+	out.WriteString(ie.Index.String())
+	out.WriteString("])")
+
+	return out.String()
+}
+
+// MemberExpression - Struct member access e.g. s.x or p->x
+type MemberExpression struct {
+	Token  token.Token // The '.' or '->' token
+	Left   Expression  // the struct, or the pointer to it for '->'
+	Member *Identifier
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(me.Left.String())
+	out.WriteString(me.Token.Literal)
+	out.WriteString(me.Member.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// IsArrow - Whether the member is accessed through a pointer
+func (me *MemberExpression) IsArrow() bool { return me.Token.Type == token.ARROW }
+
 // ReturnStatement - Return Statement
 type ReturnStatement struct {
 	Token       token.Token // the 'return' token
@@ -115,10 +241,10 @@ func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(rs.TokenLiteral() + " ")
+	out.WriteString(rs.TokenLiteral())
 
 	if rs.ReturnValue != nil {
-		out.WriteString(rs.ReturnValue.String())
+		out.WriteString(" " + rs.ReturnValue.String())
 	}
 
 	out.WriteString(";")
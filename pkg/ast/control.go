@@ -0,0 +1,222 @@
+package ast
+
+import (
+	"../token"
+	"bytes"
+)
+
+// BlockStatement - Compound statement { ... }
+type BlockStatement struct {
+	Token      token.Token // the { token
+	Statements []Statement
+}
+
+func (bs *BlockStatement) statementNode()       {}
+func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("{")
+	for _, s := range bs.Statements {
+		out.WriteString(s.String())
+	}
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// IfStatement - if (cond) stmt else stmt
+type IfStatement struct {
+	Token       token.Token // the 'if' token
+	Condition   Expression
+	Consequence Statement
+	Alternative Statement // nil when there is no else clause
+}
+
+func (is *IfStatement) statementNode()       {}
+func (is *IfStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *IfStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("if ")
+	out.WriteString(is.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(is.Consequence.String())
+
+	if is.Alternative != nil {
+		out.WriteString("else ")
+		out.WriteString(is.Alternative.String())
+	}
+
+	return out.String()
+}
+
+// WhileStatement - while (cond) stmt
+type WhileStatement struct {
+	Token     token.Token // the 'while' token
+	Condition Expression
+	Body      Statement
+}
+
+func (ws *WhileStatement) statementNode()       {}
+func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while ")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ws.Body.String())
+
+	return out.String()
+}
+
+// ForStatement - for (init; cond; step) stmt
+type ForStatement struct {
+	Token        token.Token             // the 'for' token
+	Init         Expression              // nil when omitted or declared
+	Declarations []*DeclarationStatement // the C99 declaration in place of Init, visible only in the loop
+	Condition    Expression              // nil when omitted
+	Step         Expression              // nil when omitted
+	Body         Statement
+}
+
+func (fs *ForStatement) statementNode()       {}
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	for _, decl := range fs.Declarations {
+		out.WriteString(decl.String() + " ")
+	}
+	if len(fs.Declarations) == 0 {
+		if fs.Init != nil {
+			out.WriteString(fs.Init.String())
+		}
+		out.WriteString("; ")
+	}
+	if fs.Condition != nil {
+		out.WriteString(fs.Condition.String())
+	}
+	out.WriteString("; ")
+	if fs.Step != nil {
+		out.WriteString(fs.Step.String())
+	}
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+// DoWhileStatement - do stmt while (cond);
+type DoWhileStatement struct {
+	Token     token.Token // the 'do' token
+	Body      Statement
+	Condition Expression
+}
+
+func (ds *DoWhileStatement) statementNode()       {}
+func (ds *DoWhileStatement) TokenLiteral() string { return ds.Token.Literal }
+func (ds *DoWhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("do ")
+	out.WriteString(ds.Body.String())
+	out.WriteString(" while ")
+	out.WriteString(ds.Condition.String())
+	out.WriteString(";")
+
+	return out.String()
+}
+
+// SwitchStatement - switch (tag) { case ...: ... default: ... }
+type SwitchStatement struct {
+	Token token.Token // the 'switch' token
+	Tag   Expression
+	Cases []*CaseClause // in source order; control falls through to the next clause
+}
+
+func (ss *SwitchStatement) statementNode()       {}
+func (ss *SwitchStatement) TokenLiteral() string { return ss.Token.Literal }
+func (ss *SwitchStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("switch ")
+	out.WriteString(ss.Tag.String())
+	out.WriteString(" {")
+	for _, c := range ss.Cases {
+		out.WriteString(c.String())
+	}
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// CaseClause - case CONST: stmt... or default: stmt...
+type CaseClause struct {
+	Token      token.Token // the 'case' or 'default' token
+	Value      Expression  // nil for default
+	Constant   int         // the evaluated Value
+	Statements []Statement
+}
+
+func (cc *CaseClause) TokenLiteral() string { return cc.Token.Literal }
+func (cc *CaseClause) String() string {
+	var out bytes.Buffer
+
+	if cc.IsDefault() {
+		out.WriteString("default: ")
+	} else {
+		out.WriteString("case " + cc.Value.String() + ": ")
+	}
+	for _, s := range cc.Statements {
+		out.WriteString(s.String())
+	}
+
+	return out.String()
+}
+func (cc *CaseClause) IsDefault() bool { return cc.Value == nil }
+
+// BreakStatement - break;
+type BreakStatement struct {
+	Token token.Token // the 'break' token
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) String() string       { return bs.TokenLiteral() + ";" }
+
+// ContinueStatement - continue;
+type ContinueStatement struct {
+	Token token.Token // the 'continue' token
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) String() string       { return cs.TokenLiteral() + ";" }
+
+// LabeledStatement - label: stmt
+type LabeledStatement struct {
+	Token     token.Token // the token.IDENT token
+	Label     *Identifier
+	Statement Statement
+}
+
+func (ls *LabeledStatement) statementNode()       {}
+func (ls *LabeledStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LabeledStatement) String() string {
+	return ls.Label.String() + ": " + ls.Statement.String()
+}
+
+// GotoStatement - goto label;
+type GotoStatement struct {
+	Token token.Token // the 'goto' token
+	Label *Identifier
+}
+
+func (gs *GotoStatement) statementNode()       {}
+func (gs *GotoStatement) TokenLiteral() string { return gs.Token.Literal }
+func (gs *GotoStatement) String() string {
+	return gs.TokenLiteral() + " " + gs.Label.String() + ";"
+}
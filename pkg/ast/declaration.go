@@ -6,15 +6,20 @@ import (
 )
 
 const (
-	Param = "param"
-	Local = "local"
+	Param  = "param"
+	Local  = "local"
+	Global = "global"
 )
 
 // DeclarationStatement - Varaiable declaration statement
 type DeclarationStatement struct {
-	Token    token.Token // the token.INTTYPE token
-	Name     Identifier
-	declType string
+	Token         token.Token // the token.INTTYPE token
+	Name          Identifier
+	Type          *Type
+	Value         Expression // the initializer, nil when omitted
+	Constant      int        // the evaluated Value of a global, converted to Type
+	FloatConstant float64    // the evaluated Value of a floating global
+	declType      string
 }
 
 func (ls *DeclarationStatement) statementNode()       {}
@@ -22,8 +27,11 @@ func (ls *DeclarationStatement) TokenLiteral() string { return ls.Token.Literal
 func (ls *DeclarationStatement) String() string {
 	var out bytes.Buffer
 
-	out.WriteString("int ")
-	out.WriteString(ls.Name.String())
+	out.WriteString(ls.Type.Declare(ls.Name.String()))
+	if ls.Value != nil {
+		out.WriteString(" = ")
+		out.WriteString(ls.Value.String())
+	}
 
 	out.WriteString(";")
 
@@ -33,7 +41,7 @@ func (ls *DeclarationStatement) GetDeclType() string {
 	return ls.declType
 }
 func (ls *DeclarationStatement) SetDeclType(dt string) {
-	if dt == Param || dt == Local {
+	if dt == Param || dt == Local || dt == Global {
 		ls.declType = dt
 	}
 }
@@ -8,9 +8,11 @@ import (
 
 // Prototype - Prototype declaration
 type Prototype struct {
-	Token      token.Token // the token.INTTYPE token
-	Name       *Identifier
-	Parameters []*Identifier
+	Token          token.Token // the token.INTTYPE or token.VOIDTYPE token
+	ReturnType     *Type
+	Name           *Identifier
+	Parameters     []*Identifier
+	ParameterTypes []*Type // the type of each parameter; arrays are adjusted to pointers
 }
 
 func (pt *Prototype) expressionNode()      {}
@@ -23,8 +25,7 @@ func (pt *Prototype) String() string {
 		params = append(params, p.String())
 	}
 
-	out.WriteString("int ")
-	out.WriteString(pt.TokenLiteral())
+	out.WriteString(pt.ReturnType.Declare(pt.TokenLiteral()))
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") ")
@@ -33,6 +34,7 @@ func (pt *Prototype) String() string {
 }
 func (pt *Prototype) GetName() string { return pt.Name.Name() }
 func (pt *Prototype) GetParamNum() int { return len(pt.Parameters) }
+func (pt *Prototype) IsVoid() bool { return pt.ReturnType.IsVoid() }
 
 // FunctionLiteral - function node
 type FunctionLiteral struct {
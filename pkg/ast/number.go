@@ -6,6 +6,7 @@ import "../token"
 type Number struct {
 	Token token.Token
 	Value int
+	Type  *Type // decided by the suffix and the value, int for character constants
 }
 
 func (num *Number) expressionNode()      {}
@@ -15,3 +16,14 @@ func (num *Number) String() string       { return num.Token.Literal }
 func (num *Number) Val() int {
 	return int(num.Value)
 }
+
+// FloatLiteral - Floating literal node
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+	Type  *Type // double, or float with the f suffix
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
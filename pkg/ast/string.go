@@ -0,0 +1,16 @@
+package ast
+
+import (
+	"../token"
+	"strconv"
+)
+
+// StringLiteral - String literal node
+type StringLiteral struct {
+	Token token.Token // the token.STRING token
+	Value string      // the contents with escape sequences resolved
+}
+
+func (sl *StringLiteral) expressionNode()      {}
+func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) String() string       { return strconv.Quote(sl.Value) }
@@ -0,0 +1,267 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TypeKind - Kind of a DummyC type
+type TypeKind int
+
+// integer kinds are ordered by their conversion rank
+const (
+	CharType TypeKind = iota
+	ShortType
+	IntType
+	LongType
+	LongLongType
+	FloatType
+	DoubleType
+	VoidType
+	PointerType
+	ArrayType
+	StructType
+	UnionType
+)
+
+// Type - DummyC type of a variable or parameter
+type Type struct {
+	Kind     TypeKind
+	Unsigned bool      // only for integer types
+	Elem     *Type     // the element type of pointers and arrays
+	Length   int       // the number of elements of arrays
+	Tag      string    // the tag of structs and unions, empty for anonymous ones
+	Members  []*Member // the members of structs and unions, nil while incomplete
+}
+
+// Member - Member of a struct or union
+type Member struct {
+	Name string
+	Type *Type
+}
+
+// Int - The int type
+var Int = &Type{Kind: IntType}
+
+// Char - The char type
+var Char = &Type{Kind: CharType}
+
+// Long - The long type, also the type of pointer differences
+var Long = &Type{Kind: LongType}
+
+// Float - The float type
+var Float = &Type{Kind: FloatType}
+
+// Double - The double type, also the type of floating literals without a suffix
+var Double = &Type{Kind: DoubleType}
+
+// Void - The return type of functions without a value
+var Void = &Type{Kind: VoidType}
+
+// StructOf - Incomplete struct type, completed later by DefineMembers
+func StructOf(tag string) *Type {
+	return &Type{Kind: StructType, Tag: tag}
+}
+
+// UnionOf - Incomplete union type, completed later by DefineMembers
+func UnionOf(tag string) *Type {
+	return &Type{Kind: UnionType, Tag: tag}
+}
+
+// Integer - Integer type of kind, e.g. Integer(LongType, true) is unsigned long
+func Integer(kind TypeKind, unsigned bool) *Type {
+	return &Type{Kind: kind, Unsigned: unsigned}
+}
+
+// PointerTo - Pointer type to elem
+func PointerTo(elem *Type) *Type {
+	return &Type{Kind: PointerType, Elem: elem}
+}
+
+// ArrayOf - Array type of length elems
+func ArrayOf(elem *Type, length int) *Type {
+	return &Type{Kind: ArrayType, Elem: elem, Length: length}
+}
+
+func (t *Type) IsInteger() bool    { return t.Kind <= LongLongType }
+func (t *Type) IsFloating() bool   { return t.Kind == FloatType || t.Kind == DoubleType }
+func (t *Type) IsArithmetic() bool { return t.IsInteger() || t.IsFloating() }
+func (t *Type) IsVoid() bool       { return t.Kind == VoidType }
+func (t *Type) IsPointer() bool    { return t.Kind == PointerType }
+func (t *Type) IsArray() bool      { return t.Kind == ArrayType }
+func (t *Type) IsStruct() bool     { return t.Kind == StructType }
+func (t *Type) IsUnion() bool      { return t.Kind == UnionType }
+
+// IsStructOrUnion - Whether the type has members
+func (t *Type) IsStructOrUnion() bool { return t.IsStruct() || t.IsUnion() }
+
+// IsComplete - Whether the size is known, i.e. not a struct or union before its members are defined
+func (t *Type) IsComplete() bool {
+	switch t.Kind {
+	case StructType, UnionType:
+		return t.Members != nil
+	case ArrayType:
+		return t.Elem.IsComplete()
+	}
+	return true
+}
+
+// DefineMembers - Completes the struct or union
+// The offsets of the members are left to the data layout of the generated module
+func (t *Type) DefineMembers(members []*Member) {
+	t.Members = members
+}
+
+// Member - Member of a struct or union named name, nil if there is none
+func (t *Type) Member(name string) *Member {
+	for _, member := range t.Members {
+		if member.Name == name {
+			return member
+		}
+	}
+	return nil
+}
+
+// Size - Size in bytes of an arithmetic or pointer type in the LP64 model, which decides its LLVM type
+// The size of arrays, structs and unions is left to the data layout of the generated module
+func (t *Type) Size() int {
+	switch t.Kind {
+	case CharType:
+		return 1
+	case ShortType:
+		return 2
+	case IntType, FloatType:
+		return 4
+	case LongType, LongLongType, DoubleType, PointerType:
+		return 8
+	}
+	return 0
+}
+
+// Promoted - Integer promotion, integers of lower rank than int become int
+func (t *Type) Promoted() *Type {
+	if t.IsInteger() && t.Kind < IntType {
+		return Int
+	}
+	return t
+}
+
+// SameAs - Whether t and u are the same type, structs and unions only when declared by the same specifier
+func (t *Type) SameAs(u *Type) bool {
+	if t == u {
+		return true
+	}
+	if t.Kind != u.Kind || t.Unsigned != u.Unsigned {
+		return false
+	}
+
+	switch t.Kind {
+	case PointerType:
+		return t.Elem.SameAs(u.Elem)
+	case ArrayType:
+		return t.Length == u.Length && t.Elem.SameAs(u.Elem)
+	case StructType, UnionType:
+		return false
+	}
+	return true
+}
+
+// Normalize - Integer value converted to the type, truncated to its size and sign or zero extended
+// 64-bit unsigned values keep their bit pattern
+func (t *Type) Normalize(value int) int {
+	bits := uint(t.Size() * 8)
+	if bits >= 64 {
+		return value
+	}
+
+	value &= 1<<bits - 1
+	if !t.Unsigned && value >= 1<<(bits-1) {
+		value -= 1 << bits
+	}
+	return value
+}
+
+// CommonType - Usual arithmetic conversions of two arithmetic types
+func CommonType(lhs, rhs *Type) *Type {
+	// a floating type wins, double over float
+	if lhs.IsFloating() || rhs.IsFloating() {
+		if lhs.IsFloating() && (!rhs.IsFloating() || lhs.Kind >= rhs.Kind) {
+			return lhs
+		}
+		return rhs
+	}
+
+	lhs, rhs = lhs.Promoted(), rhs.Promoted()
+
+	// same signedness: the higher rank
+	if lhs.Unsigned == rhs.Unsigned {
+		if lhs.Kind >= rhs.Kind {
+			return lhs
+		}
+		return rhs
+	}
+
+	signed, unsigned := lhs, rhs
+	if lhs.Unsigned {
+		signed, unsigned = rhs, lhs
+	}
+
+	switch {
+	case unsigned.Kind >= signed.Kind:
+		return unsigned
+	case signed.Size() > unsigned.Size():
+		// the signed type can represent all values of the unsigned type
+		return signed
+	default:
+		return Integer(signed.Kind, true)
+	}
+}
+
+// Declare - C declaration of name with this type, e.g. int a[10]
+func (t *Type) Declare(name string) string {
+	switch t.Kind {
+	case PointerType:
+		name = "*" + name
+		if t.Elem.IsArray() {
+			name = "(" + name + ")"
+		}
+		return t.Elem.Declare(name)
+	case ArrayType:
+		return t.Elem.Declare(fmt.Sprintf("%s[%d]", name, t.Length))
+	case VoidType:
+		return strings.TrimSpace("void " + name)
+	case StructType, UnionType:
+		tag := t.Tag
+		if tag == "" {
+			tag = "<anonymous>"
+		}
+		return strings.TrimSpace(t.Keyword() + " " + tag + " " + name)
+	}
+
+	specifier := basicNames[t.Kind]
+	if t.Unsigned {
+		specifier = "unsigned " + specifier
+	}
+	return strings.TrimSpace(specifier + " " + name)
+}
+
+// Keyword - The keyword that declares the type, struct or union
+func (t *Type) Keyword() string {
+	if t.IsUnion() {
+		return "union"
+	}
+	return "struct"
+}
+
+var basicNames = map[TypeKind]string{
+	CharType:     "char",
+	ShortType:    "short",
+	IntType:      "int",
+	LongType:     "long",
+	LongLongType: "long long",
+	FloatType:    "float",
+	DoubleType:   "double",
+}
+
+// String - C type name, e.g. int *
+func (t *Type) String() string { return t.Declare("") }
@@ -4,22 +4,65 @@ import (
 	"../ast"
 	"fmt"
 	"llvm.org/llvm/bindings/go/llvm"
+	"strings"
 )
 
 type CodeGen struct {
-	curFunc   *llvm.Value  // 現在コード生成中のFunction
-	mod       *llvm.Module // 生成したModuleを格納
-	builder   llvm.Builder // LLVM-IRを生成するIRBuilderクラス
-	variables map[string]*llvm.Value
+	curFunc   *llvm.Value                // 現在コード生成中のFunction
+	mod       *llvm.Module               // 生成したModuleを格納
+	builder   llvm.Builder               // LLVM-IRを生成するIRBuilderクラス
+	globals   map[string]*variable       // 大域変数
+	variables []map[string]*variable     // ブロックごとの変数 内側のブロックほど後ろ
+	functions map[string]*ast.Prototype  // 宣言済みの関数 呼び出しの型に使う
+	structs   map[*ast.Type]llvm.Type    // 構造体の型に対応する名前付きの構造体型
+	loops     []loopContext              // 生成中のループとswitch 内側ほど後ろ
+	labels    map[string]llvm.BasicBlock // 関数内のラベルに対応するブロック
+	triple    string                     // 生成したモジュールを実行するホストのターゲット
+	layout    string                     // ホストのデータレイアウト 構造体のメンバの位置はLLVMがこれで決める
+}
+
+// variable - 変数のアドレス(仮引数は値)と宣言された型
+type variable struct {
+	value *llvm.Value
+	typ   *ast.Type
+}
+
+// loopContext - break/continueの飛び先 switchではcontinueBlockは外側のループのもの
+type loopContext struct {
+	breakBlock    llvm.BasicBlock
+	continueBlock llvm.BasicBlock
 }
 
 func New() *CodeGen {
 	cg := &CodeGen{}
-	cg.variables = map[string]*llvm.Value{}
+	cg.globals = map[string]*variable{}
+	cg.variables = []map[string]*variable{{}}
+	cg.functions = map[string]*ast.Prototype{}
+	cg.structs = map[*ast.Type]llvm.Type{}
 	cg.builder = llvm.NewBuilder()
+	cg.triple, cg.layout = nativeTarget()
 	return cg
 }
 
+// nativeTarget - モジュールはJITで実行するので、ホストのターゲットとデータレイアウトを使う
+// 整数の幅はast.TypeのSizeのLP64の値で、構造体と共用体の配置はこのデータレイアウトでLLVMが決める
+func nativeTarget() (triple, layout string) {
+	if err := llvm.InitializeNativeTarget(); err != nil {
+		panic(err)
+	}
+
+	triple = llvm.DefaultTargetTriple()
+	target, err := llvm.GetTargetFromTriple(triple)
+	if err != nil {
+		panic(err)
+	}
+	machine := target.CreateTargetMachine(triple, "", "", llvm.CodeGenLevelDefault, llvm.RelocDefault, llvm.CodeModelDefault)
+	defer machine.Dispose()
+	td := machine.CreateTargetData()
+	defer td.Dispose()
+	return triple, td.String()
+}
+
 func (cg *CodeGen) GetModule() llvm.Module {
 	if cg.mod != nil {
 		return *cg.mod
@@ -39,8 +82,15 @@ func (cg *CodeGen) Generate(tu *ast.TranslationUnit, name, linkfile string) bool
 // generateTranslationUnit - モジュール生成メソッド
 func (cg *CodeGen) generateTranslationUnit(tu *ast.TranslationUnit, name string) bool {
 	module := llvm.NewModule(name)
+	module.SetTarget(cg.triple)
+	module.SetDataLayout(cg.layout)
 	cg.mod = &module
 
+	// global variable
+	for _, vdecl := range tu.Globals {
+		cg.generateGlobalVariable(&vdecl)
+	}
+
 	// function declaration
 	for _, proto := range tu.Prototypes {
 		cg.generatePrototype(&proto, cg.mod)
@@ -56,42 +106,40 @@ func (cg *CodeGen) generateTranslationUnit(tu *ast.TranslationUnit, name string)
 
 func (cg *CodeGen) generatePrototype(prototype *ast.Prototype, mod *llvm.Module) llvm.Value {
 
-	// 既に定義済みか
-	{
-		function := mod.NamedFunction(prototype.GetName())
-		if !function.IsNil() {
-			if function.ParamsCount() == len(prototype.Parameters) {
-				return function
-			} else {
-				msg := fmt.Sprintf("error::function %s is redefined", prototype.GetName())
-				panic(msg)
-			}
-		}
-	}
-
-	// まだ未定義のとき
 	// create arg_types
-	intTypes := make([]llvm.Type, len(prototype.Parameters))
+	paramTypes := make([]llvm.Type, len(prototype.Parameters))
 	for i := 0; i < len(prototype.Parameters); i++ {
-		intTypes[i] = llvm.Int32Type()
+		paramTypes[i] = cg.llvmType(prototype.ParameterTypes[i])
 	}
 
 	// create func type
-	functionType := llvm.FunctionType(llvm.Int32Type(), intTypes, false)
+	returnType := cg.llvmType(prototype.ReturnType)
+	functionType := llvm.FunctionType(returnType, paramTypes, false)
 
 	// create function
-	function := llvm.AddFunction(*mod, prototype.GetName(), functionType)
+	// 既に宣言済みなら型が同じときだけ同じ関数とみなす
+	function := mod.NamedFunction(prototype.GetName())
+	if function.IsNil() {
+		function = llvm.AddFunction(*mod, prototype.GetName(), functionType)
+	} else if function.Type().ElementType() != functionType {
+		msg := fmt.Sprintf("error::function %s is redefined", prototype.GetName())
+		panic(msg)
+	}
+	cg.functions[prototype.GetName()] = prototype
+
+	// 関数定義では仮引数の名前が宣言と異なってもよい
 	for i := range function.Params() {
 		paramName := prototype.Parameters[i].Name() + "_arg"
 		function.Params()[i].SetName(paramName)
-		cg.variables[paramName] = &function.Params()[i]
+		cg.declareVariable(paramName, &function.Params()[i], prototype.ParameterTypes[i])
 	}
 
 	return function
 }
 
 func (cg *CodeGen) generateFunctionDefinition(functionLiteral *ast.FunctionLiteral, mod *llvm.Module) llvm.Value {
-	cg.variables = map[string]*llvm.Value{}
+	cg.variables = []map[string]*variable{{}}
+	cg.labels = map[string]llvm.BasicBlock{}
 	function := cg.generatePrototype(&functionLiteral.Prototype, mod)
 	cg.curFunc = &function
 
@@ -101,6 +149,15 @@ func (cg *CodeGen) generateFunctionDefinition(functionLiteral *ast.FunctionLiter
 	// TODO: Functionのボディを生成
 	cg.generateFunctionStatement(&functionLiteral.Body)
 
+	// 末尾に到達したとき voidなら戻り、それ以外は0を返す
+	// main以外の関数は末尾に到達しないことを構文解析で確認済みだが、未定義動作にはしない
+	if functionLiteral.Prototype.IsVoid() {
+		cg.builder.CreateRetVoid()
+	} else {
+		returnType := cg.llvmType(functionLiteral.Prototype.ReturnType)
+		cg.builder.CreateRet(llvm.ConstNull(returnType))
+	}
+
 	return function
 }
 
@@ -121,23 +178,162 @@ func (cg *CodeGen) generateFunctionStatement(functionStmt *ast.FunctionStatement
 	return &v
 }
 
+// generateGlobalVariable - 初期化式がなければ0で初期化する
+func (cg *CodeGen) generateGlobalVariable(vdecl *ast.DeclarationStatement) *llvm.Value {
+	t := cg.llvmType(vdecl.Type)
+	global := llvm.AddGlobal(*cg.mod, t, vdecl.Name.Name())
+	if vdecl.Type.IsArray() || vdecl.Type.IsPointer() || vdecl.Type.IsStructOrUnion() {
+		global.SetInitializer(llvm.ConstNull(t))
+	} else if vdecl.Type.IsFloating() {
+		global.SetInitializer(llvm.ConstFloat(t, vdecl.FloatConstant))
+	} else {
+		global.SetInitializer(llvm.ConstInt(t, uint64(vdecl.Constant), true))
+	}
+	cg.globals[vdecl.Name.Name()] = &variable{&global, vdecl.Type}
+
+	return &global
+}
+
 func (cg *CodeGen) generateVariableDeclaration(vdecl *ast.DeclarationStatement) *llvm.Value {
 
 	// create alloca
-	alloca := cg.builder.CreateAlloca(llvm.Int32Type(), vdecl.Name.Name())
-	cg.variables[vdecl.Name.Name()] = &alloca
+	alloca := cg.createEntryAlloca(cg.llvmType(vdecl.Type), vdecl.Name.Name())
+	cg.declareVariable(vdecl.Name.Name(), &alloca, vdecl.Type)
 
 	// store args
 	if vdecl.GetDeclType() == ast.Param {
-		v := *cg.variables[vdecl.Name.Name()+"_arg"]
+		v := *cg.lookupVariable(vdecl.Name.Name() + "_arg").value
 		v = cg.builder.CreateStore(v, alloca)
 	}
 
+	// 初期化式は宣言した位置で評価する
+	if vdecl.Value != nil {
+		v := cg.generateExpressionAs(vdecl.Value, vdecl.Type)
+		cg.builder.CreateStore(v, alloca)
+	}
+
 	return &alloca
 }
 
+// llvmType - DummyCの型に対応するLLVMの型
+func (cg *CodeGen) llvmType(t *ast.Type) llvm.Type {
+	switch t.Kind {
+	case ast.PointerType:
+		return llvm.PointerType(cg.llvmType(t.Elem), 0)
+	case ast.ArrayType:
+		return llvm.ArrayType(cg.llvmType(t.Elem), t.Length)
+	case ast.VoidType:
+		return llvm.VoidType()
+	case ast.FloatType:
+		return llvm.FloatType()
+	case ast.DoubleType:
+		return llvm.DoubleType()
+	case ast.StructType, ast.UnionType:
+		return cg.structType(t)
+	}
+	return llvm.IntType(t.Size() * 8)
+}
+
+// structType - 構造体はメンバを宣言順に並べた名前付きの構造体型にする 不完全型は中身のない型
+// 共用体は最も大きいメンバが収まる構造体型 メンバはポインタを変換して読み書きする
+// 自身を指すポインタのメンバがあるので、中身を決める前に登録する
+func (cg *CodeGen) structType(t *ast.Type) llvm.Type {
+	if st, ok := cg.structs[t]; ok {
+		return st
+	}
+
+	name := t.Keyword() + "." + t.Tag
+	if t.Tag == "" {
+		name = t.Keyword() + ".anon"
+	}
+	st := llvm.GlobalContext().StructCreateNamed(name)
+	cg.structs[t] = st
+
+	if t.IsComplete() && t.IsUnion() {
+		st.StructSetBody(cg.unionBody(t), false)
+	} else if t.IsComplete() {
+		elems := make([]llvm.Type, len(t.Members))
+		for i, member := range t.Members {
+			elems[i] = cg.llvmType(member.Type)
+		}
+		st.StructSetBody(elems, false)
+	}
+	return st
+}
+
+// unionBody - 共用体の中身 境界の最も大きいメンバを先頭に置き、最も大きいメンバが収まるまでi8の配列で埋める
+// 大きさと境界はデータレイアウトでメンバのLLVMの型から求める
+func (cg *CodeGen) unionBody(t *ast.Type) []llvm.Type {
+	td := llvm.NewTargetData(cg.layout)
+	defer td.Dispose()
+
+	var lead llvm.Type
+	size := 0
+	for i, member := range t.Members {
+		mt := cg.llvmType(member.Type)
+		if i == 0 || td.ABITypeAlignment(mt) > td.ABITypeAlignment(lead) {
+			lead = mt
+		}
+		if s := int(td.TypeAllocSize(mt)); s > size {
+			size = s
+		}
+	}
+
+	elems := []llvm.Type{lead}
+	if padding := size - int(td.TypeAllocSize(lead)); padding > 0 {
+		elems = append(elems, llvm.ArrayType(llvm.Int8Type(), padding))
+	}
+	return elems
+}
+
+// createEntryAlloca - allocaは宣言の位置に関わらず関数の先頭ブロックに置く
+// ループ内の宣言でスタックが伸びず、mem2regでレジスタに昇格できる
+func (cg *CodeGen) createEntryAlloca(t llvm.Type, name string) llvm.Value {
+	builder := llvm.NewBuilder()
+	defer builder.Dispose()
+
+	entry := cg.curFunc.EntryBasicBlock()
+	if first := entry.FirstInstruction(); first.IsNil() {
+		builder.SetInsertPointAtEnd(entry)
+	} else {
+		builder.SetInsertPointBefore(first)
+	}
+	return builder.CreateAlloca(t, name)
+}
+
+func (cg *CodeGen) pushScope() {
+	cg.variables = append(cg.variables, map[string]*variable{})
+}
+
+func (cg *CodeGen) popScope() {
+	cg.variables = cg.variables[:len(cg.variables)-1]
+}
+
+// declareVariable - 現在のブロックに変数を登録する
+func (cg *CodeGen) declareVariable(name string, v *llvm.Value, t *ast.Type) {
+	cg.variables[len(cg.variables)-1][name] = &variable{v, t}
+}
+
+// lookupVariable - 内側のブロックから順に変数を探し、最後に大域変数を探す
+func (cg *CodeGen) lookupVariable(name string) *variable {
+	for i := len(cg.variables) - 1; i >= 0; i-- {
+		if v, ok := cg.variables[i][name]; ok {
+			return v
+		}
+	}
+	if v, ok := cg.globals[name]; ok {
+		return v
+	}
+	msg := fmt.Sprintf("%s is not declared", name)
+	panic(msg)
+}
+
 func (cg *CodeGen) generateStatement(stmt ast.Statement) llvm.Value {
 
+	if declStmt, ok := stmt.(*ast.DeclarationStatement); ok {
+		return *cg.generateVariableDeclaration(declStmt)
+	}
+
 	if returnStmt, ok := stmt.(*ast.ReturnStatement); ok {
 		return cg.generateReturnStatement(returnStmt)
 	}
@@ -146,60 +342,550 @@ func (cg *CodeGen) generateStatement(stmt ast.Statement) llvm.Value {
 		return cg.generateExpressionStatement(exprStmt)
 	}
 
+	if ifStmt, ok := stmt.(*ast.IfStatement); ok {
+		return cg.generateIfStatement(ifStmt)
+	}
+
+	if blockStmt, ok := stmt.(*ast.BlockStatement); ok {
+		return cg.generateBlockStatement(blockStmt)
+	}
+
+	if whileStmt, ok := stmt.(*ast.WhileStatement); ok {
+		return cg.generateWhileStatement(whileStmt)
+	}
+
+	if forStmt, ok := stmt.(*ast.ForStatement); ok {
+		return cg.generateForStatement(forStmt)
+	}
+
+	if doWhileStmt, ok := stmt.(*ast.DoWhileStatement); ok {
+		return cg.generateDoWhileStatement(doWhileStmt)
+	}
+
+	if switchStmt, ok := stmt.(*ast.SwitchStatement); ok {
+		return cg.generateSwitchStatement(switchStmt)
+	}
+
+	if labeledStmt, ok := stmt.(*ast.LabeledStatement); ok {
+		return cg.generateLabeledStatement(labeledStmt)
+	}
+
+	if gotoStmt, ok := stmt.(*ast.GotoStatement); ok {
+		return cg.generateGotoStatement(gotoStmt)
+	}
+
+	if breakStmt, ok := stmt.(*ast.BreakStatement); ok {
+		return cg.generateBreakStatement(breakStmt)
+	}
+
+	if continueStmt, ok := stmt.(*ast.ContinueStatement); ok {
+		return cg.generateContinueStatement(continueStmt)
+	}
+
 	panic("generateStatement")
 }
 
-func (cg *CodeGen) generateExpressionStatement(exprStmt *ast.ExpressionStatement) llvm.Value {
-	expr := exprStmt.Expression
+func (cg *CodeGen) generateBlockStatement(blockStmt *ast.BlockStatement) llvm.Value {
+	var v llvm.Value
 
-	if infixStmt, ok := expr.(*ast.InfixExpression); ok {
-		return cg.generateInfixExpression(infixStmt)
+	cg.pushScope()
+	for _, stmt := range blockStmt.Statements {
+		v = cg.generateStatement(stmt)
 	}
+	cg.popScope()
+
+	return v
+}
+
+func (cg *CodeGen) generateIfStatement(ifStmt *ast.IfStatement) llvm.Value {
+	cond := cg.generateCondition(ifStmt.Condition)
 
-	if callStmt, ok := expr.(*ast.CallExpression); ok {
-		return cg.generateCallExpression(callStmt)
+	thenBlock := llvm.AddBasicBlock(*cg.curFunc, "if_then")
+	var elseBlock llvm.BasicBlock
+	if ifStmt.Alternative != nil {
+		elseBlock = llvm.AddBasicBlock(*cg.curFunc, "if_else")
 	}
+	mergeBlock := llvm.AddBasicBlock(*cg.curFunc, "if_merge")
 
-	panic("generateExpressionStatement")
+	// else節がなければ条件が偽のときmergeへ飛ぶ
+	falseBlock := mergeBlock
+	if ifStmt.Alternative != nil {
+		falseBlock = elseBlock
+	}
+	br := cg.builder.CreateCondBr(cond, thenBlock, falseBlock)
+
+	cg.builder.SetInsertPointAtEnd(thenBlock)
+	cg.generateStatement(ifStmt.Consequence)
+	cg.builder.CreateBr(mergeBlock)
+
+	if ifStmt.Alternative != nil {
+		cg.builder.SetInsertPointAtEnd(elseBlock)
+		cg.generateStatement(ifStmt.Alternative)
+		cg.builder.CreateBr(mergeBlock)
+	}
+
+	cg.builder.SetInsertPointAtEnd(mergeBlock)
+	return br
 }
 
-func (cg *CodeGen) generateInfixExpression(infixStmt *ast.InfixExpression) llvm.Value {
-	lhs := infixStmt.Left
-	rhs := infixStmt.Right
-	var lhsValue, rhsValue llvm.Value
-
-	if infixStmt.Operator == "=" {
-		// lhs is variable
-		lhsVariable := lhs.(*ast.Identifier)
-		lhsName := lhsVariable.Name()
-		lhsValue = *cg.variables[lhsName]
+func (cg *CodeGen) generateWhileStatement(whileStmt *ast.WhileStatement) llvm.Value {
+	condBlock := llvm.AddBasicBlock(*cg.curFunc, "while_cond")
+	bodyBlock := llvm.AddBasicBlock(*cg.curFunc, "while_body")
+	endBlock := llvm.AddBasicBlock(*cg.curFunc, "while_end")
+
+	cg.builder.CreateBr(condBlock)
+
+	cg.builder.SetInsertPointAtEnd(condBlock)
+	cond := cg.generateCondition(whileStmt.Condition)
+	br := cg.builder.CreateCondBr(cond, bodyBlock, endBlock)
+
+	cg.builder.SetInsertPointAtEnd(bodyBlock)
+	cg.pushLoop(endBlock, condBlock)
+	cg.generateStatement(whileStmt.Body)
+	cg.popLoop()
+	cg.builder.CreateBr(condBlock)
+
+	cg.builder.SetInsertPointAtEnd(endBlock)
+	return br
+}
+
+func (cg *CodeGen) generateForStatement(forStmt *ast.ForStatement) llvm.Value {
+	// 初期化節で宣言した変数はループを抜けると見えなくなる
+	cg.pushScope()
+	for _, decl := range forStmt.Declarations {
+		cg.generateVariableDeclaration(decl)
+	}
+	if forStmt.Init != nil {
+		cg.generateDiscardedExpression(forStmt.Init)
+	}
+
+	condBlock := llvm.AddBasicBlock(*cg.curFunc, "for_cond")
+	bodyBlock := llvm.AddBasicBlock(*cg.curFunc, "for_body")
+	stepBlock := llvm.AddBasicBlock(*cg.curFunc, "for_step")
+	endBlock := llvm.AddBasicBlock(*cg.curFunc, "for_end")
+
+	br := cg.builder.CreateBr(condBlock)
+
+	// 条件式が省略されていれば無限ループ
+	cg.builder.SetInsertPointAtEnd(condBlock)
+	if forStmt.Condition != nil {
+		cond := cg.generateCondition(forStmt.Condition)
+		cg.builder.CreateCondBr(cond, bodyBlock, endBlock)
 	} else {
-		// lhs ?
-		// Binary ?
-		if infixStmt, ok := lhs.(*ast.InfixExpression); ok {
-			lhsValue = cg.generateInfixExpression(infixStmt)
-		} else if identStmt, ok := lhs.(*ast.Identifier); ok {
-			lhsValue = cg.generateIdentifier(identStmt)
-		} else if numberStmt, ok := lhs.(*ast.Number); ok {
-			lhsValue = cg.generateNumber(numberStmt.Val())
+		cg.builder.CreateBr(bodyBlock)
+	}
+
+	// continueはstepへ飛ぶ
+	cg.builder.SetInsertPointAtEnd(bodyBlock)
+	cg.pushLoop(endBlock, stepBlock)
+	cg.generateStatement(forStmt.Body)
+	cg.popLoop()
+	cg.builder.CreateBr(stepBlock)
+
+	cg.builder.SetInsertPointAtEnd(stepBlock)
+	if forStmt.Step != nil {
+		cg.generateDiscardedExpression(forStmt.Step)
+	}
+	cg.builder.CreateBr(condBlock)
+	cg.popScope()
+
+	cg.builder.SetInsertPointAtEnd(endBlock)
+	return br
+}
+
+func (cg *CodeGen) generateDoWhileStatement(doWhileStmt *ast.DoWhileStatement) llvm.Value {
+	bodyBlock := llvm.AddBasicBlock(*cg.curFunc, "do_body")
+	condBlock := llvm.AddBasicBlock(*cg.curFunc, "do_cond")
+	endBlock := llvm.AddBasicBlock(*cg.curFunc, "do_end")
+
+	// 本体を先に1度実行する
+	br := cg.builder.CreateBr(bodyBlock)
+
+	cg.builder.SetInsertPointAtEnd(bodyBlock)
+	cg.pushLoop(endBlock, condBlock)
+	cg.generateStatement(doWhileStmt.Body)
+	cg.popLoop()
+	cg.builder.CreateBr(condBlock)
+
+	cg.builder.SetInsertPointAtEnd(condBlock)
+	cond := cg.generateCondition(doWhileStmt.Condition)
+	cg.builder.CreateCondBr(cond, bodyBlock, endBlock)
+
+	cg.builder.SetInsertPointAtEnd(endBlock)
+	return br
+}
+
+func (cg *CodeGen) generateSwitchStatement(switchStmt *ast.SwitchStatement) llvm.Value {
+	// caseの定数は整数拡張した制御式の型に合わせる
+	tag := cg.generateExpression(switchStmt.Tag)
+	tagType := cg.typeOf(switchStmt.Tag)
+	if !tagType.IsInteger() {
+		msg := fmt.Sprintf("switch quantity %s is not an integer", switchStmt.Tag.String())
+		panic(msg)
+	}
+	tag = cg.convert(tag, tagType, tagType.Promoted())
+
+	caseBlocks := make([]llvm.BasicBlock, len(switchStmt.Cases))
+	for i, clause := range switchStmt.Cases {
+		if clause.IsDefault() {
+			caseBlocks[i] = llvm.AddBasicBlock(*cg.curFunc, "switch_default")
+		} else {
+			caseBlocks[i] = llvm.AddBasicBlock(*cg.curFunc, "switch_case")
+		}
+	}
+	endBlock := llvm.AddBasicBlock(*cg.curFunc, "switch_end")
+
+	// defaultがなければどのcaseにも一致しないときswitchの後ろへ飛ぶ
+	defaultBlock := endBlock
+	for i, clause := range switchStmt.Cases {
+		if clause.IsDefault() {
+			defaultBlock = caseBlocks[i]
+		}
+	}
+
+	// caseの定数を制御式の型に変換してから重複を調べる intの制御式では1と4294967297Lは同じ値
+	sw := cg.builder.CreateSwitch(tag, defaultBlock, len(switchStmt.Cases))
+	values := map[int]bool{}
+	for i, clause := range switchStmt.Cases {
+		if clause.IsDefault() {
+			continue
+		}
+		value := tagType.Promoted().Normalize(clause.Constant)
+		if values[value] {
+			msg := fmt.Sprintf("duplicate case value %s", clause.Value.String())
+			panic(msg)
+		}
+		values[value] = true
+		sw.AddCase(llvm.ConstInt(tag.Type(), uint64(value), true), caseBlocks[i])
+	}
+
+	// breakがなければ次の節へフォールスルーする
+	cg.pushSwitch(endBlock)
+	cg.pushScope()
+	for i, clause := range switchStmt.Cases {
+		cg.builder.SetInsertPointAtEnd(caseBlocks[i])
+		for _, stmt := range clause.Statements {
+			cg.generateStatement(stmt)
+		}
+
+		if i+1 < len(caseBlocks) {
+			cg.builder.CreateBr(caseBlocks[i+1])
+		} else {
+			cg.builder.CreateBr(endBlock)
+		}
+	}
+	cg.popScope()
+	cg.popLoop()
+
+	cg.builder.SetInsertPointAtEnd(endBlock)
+	return sw
+}
+
+func (cg *CodeGen) generateBreakStatement(breakStmt *ast.BreakStatement) llvm.Value {
+	if len(cg.loops) == 0 {
+		panic("break statement not within loop or switch")
+	}
+
+	br := cg.builder.CreateBr(cg.loops[len(cg.loops)-1].breakBlock)
+	cg.enterDeadBlock("after_break")
+	return br
+}
+
+func (cg *CodeGen) generateContinueStatement(continueStmt *ast.ContinueStatement) llvm.Value {
+	if len(cg.loops) == 0 || cg.loops[len(cg.loops)-1].continueBlock.IsNil() {
+		panic("continue statement not within loop")
+	}
+
+	br := cg.builder.CreateBr(cg.loops[len(cg.loops)-1].continueBlock)
+	cg.enterDeadBlock("after_continue")
+	return br
+}
+
+func (cg *CodeGen) generateLabeledStatement(labeledStmt *ast.LabeledStatement) llvm.Value {
+	block := cg.labelBlock(labeledStmt.Label.Name())
+
+	// 直前の文からラベルへ流れ込む
+	cg.builder.CreateBr(block)
+	cg.builder.SetInsertPointAtEnd(block)
+
+	return cg.generateStatement(labeledStmt.Statement)
+}
+
+func (cg *CodeGen) generateGotoStatement(gotoStmt *ast.GotoStatement) llvm.Value {
+	br := cg.builder.CreateBr(cg.labelBlock(gotoStmt.Label.Name()))
+	cg.enterDeadBlock("after_goto")
+	return br
+}
+
+// labelBlock - ラベルに対応するブロックを返す gotoが前方参照する場合もあるので初めて使われたときに作る
+func (cg *CodeGen) labelBlock(name string) llvm.BasicBlock {
+	if block, ok := cg.labels[name]; ok {
+		return block
+	}
+
+	block := llvm.AddBasicBlock(*cg.curFunc, "label_"+name)
+	cg.labels[name] = block
+	return block
+}
+
+func (cg *CodeGen) pushLoop(breakBlock, continueBlock llvm.BasicBlock) {
+	cg.loops = append(cg.loops, loopContext{breakBlock, continueBlock})
+}
+
+// pushSwitch - switch内のbreakはswitchの後ろへ、continueは外側のループへ飛ぶ
+func (cg *CodeGen) pushSwitch(breakBlock llvm.BasicBlock) {
+	var continueBlock llvm.BasicBlock
+	if len(cg.loops) > 0 {
+		continueBlock = cg.loops[len(cg.loops)-1].continueBlock
+	}
+	cg.loops = append(cg.loops, loopContext{breakBlock, continueBlock})
+}
+
+func (cg *CodeGen) popLoop() {
+	cg.loops = cg.loops[:len(cg.loops)-1]
+}
+
+// enterDeadBlock - 無条件ジャンプ以降の文は到達不能な新しいブロックに生成する
+func (cg *CodeGen) enterDeadBlock(name string) {
+	cg.builder.SetInsertPointAtEnd(llvm.AddBasicBlock(*cg.curFunc, name))
+}
+
+// generateCondition - 式を評価して0と比較し、分岐に使うi1の値を返す
+func (cg *CodeGen) generateCondition(expr ast.Expression) llvm.Value {
+	value := cg.generateExpression(expr)
+	t := cg.typeOf(expr)
+	if !t.IsArithmetic() && !t.IsPointer() {
+		msg := fmt.Sprintf("%s is used as a condition but is not a scalar", expr.String())
+		panic(msg)
+	}
+
+	zero := llvm.ConstNull(value.Type()) // ポインタはヌルポインタ、浮動小数点数は0.0と比べる
+	if t.IsFloating() {
+		return cg.builder.CreateFCmp(llvm.FloatUNE, value, zero, "cond")
+	}
+	return cg.builder.CreateICmp(llvm.IntNE, value, zero, "cond")
+}
+
+// generateExpression - 式の種類によらず値を生成する 式が現れる全ての場所から使う
+func (cg *CodeGen) generateExpression(expr ast.Expression) llvm.Value {
+	switch expr := expr.(type) {
+	case *ast.InfixExpression:
+		return cg.generateInfixExpression(expr)
+	case *ast.PrefixExpression:
+		return cg.generatePrefixExpression(expr)
+	case *ast.PostfixExpression:
+		return cg.generatePostfixExpression(expr)
+	case *ast.ConditionalExpression:
+		return cg.generateConditionalExpression(expr)
+	case *ast.GroupedExpression:
+		return cg.generateExpression(expr.Expression)
+	case *ast.IndexExpression:
+		return cg.loadValue(cg.generateIndexAddress(expr))
+	case *ast.MemberExpression:
+		return cg.loadValue(cg.generateMemberAddress(expr))
+	case *ast.CallExpression:
+		call := cg.generateCallExpression(expr)
+		if call.Type().TypeKind() == llvm.VoidTypeKind {
+			msg := fmt.Sprintf("void value of %s is used", expr.String())
+			panic(msg)
 		}
+		return call
+	case *ast.Identifier:
+		return cg.generateIdentifier(expr)
+	case *ast.StringLiteral:
+		// 末尾に\0を付けたi8の配列を定数として置き、その先頭を指す
+		return cg.builder.CreateGlobalStringPtr(expr.Value, "str")
+	case *ast.Number:
+		return cg.generateNumber(expr)
+	case *ast.FloatLiteral:
+		return llvm.ConstFloat(cg.llvmType(expr.Type), expr.Value)
 	}
 
-	// create rhs value
-	if infixStmt, ok := rhs.(*ast.InfixExpression); ok {
-		rhsValue = cg.generateInfixExpression(infixStmt)
-	} else if callStmt, ok := rhs.(*ast.CallExpression); ok {
-		rhsValue = cg.generateCallExpression(callStmt)
-	} else if identStmt, ok := rhs.(*ast.Identifier); ok {
-		rhsValue = cg.generateIdentifier(identStmt)
-	} else if numberStmt, ok := rhs.(*ast.Number); ok {
-		rhsValue = cg.generateNumber(numberStmt.Val())
+	msg := fmt.Sprintf("generateExpression: unsupported expression %T", expr)
+	panic(msg)
+}
+
+func (cg *CodeGen) generateExpressionStatement(exprStmt *ast.ExpressionStatement) llvm.Value {
+	// 空文
+	if exprStmt.Expression == nil {
+		return llvm.Value{}
+	}
+
+	return cg.generateDiscardedExpression(exprStmt.Expression)
+}
+
+// generateDiscardedExpression - 値を捨てる式ではvoid関数も呼べる 括弧の中や条件演算子の枝でも同じ
+func (cg *CodeGen) generateDiscardedExpression(expr ast.Expression) llvm.Value {
+	switch expr := expr.(type) {
+	case *ast.CallExpression:
+		return cg.generateCallExpression(expr)
+	case *ast.GroupedExpression:
+		return cg.generateDiscardedExpression(expr.Expression)
+	case *ast.ConditionalExpression:
+		if cg.typeOf(expr.Consequence).IsVoid() || cg.typeOf(expr.Alternative).IsVoid() {
+			return cg.generateVoidConditional(expr)
+		}
+	}
+
+	return cg.generateExpression(expr)
+}
+
+// generateVoidConditional - c ? f() : g() のvoidの条件演算子 値を作らずに分岐だけする
+// 片方の枝がvoidならもう片方もvoidでなければならない
+func (cg *CodeGen) generateVoidConditional(condExpr *ast.ConditionalExpression) llvm.Value {
+	if !cg.typeOf(condExpr.Consequence).IsVoid() || !cg.typeOf(condExpr.Alternative).IsVoid() {
+		msg := fmt.Sprintf("operands of %s have incompatible types", condExpr.String())
+		panic(msg)
 	}
 
-	// execute op
+	cond := cg.generateCondition(condExpr.Condition)
+
+	trueBlock := llvm.AddBasicBlock(*cg.curFunc, "cond_true")
+	falseBlock := llvm.AddBasicBlock(*cg.curFunc, "cond_false")
+	mergeBlock := llvm.AddBasicBlock(*cg.curFunc, "cond_merge")
+	br := cg.builder.CreateCondBr(cond, trueBlock, falseBlock)
+
+	cg.builder.SetInsertPointAtEnd(trueBlock)
+	cg.generateDiscardedExpression(condExpr.Consequence)
+	cg.builder.CreateBr(mergeBlock)
+
+	cg.builder.SetInsertPointAtEnd(falseBlock)
+	cg.generateDiscardedExpression(condExpr.Alternative)
+	cg.builder.CreateBr(mergeBlock)
+
+	cg.builder.SetInsertPointAtEnd(mergeBlock)
+	return br
+}
+
+func (cg *CodeGen) generateInfixExpression(infixStmt *ast.InfixExpression) llvm.Value {
 	switch infixStmt.Operator {
+	case "&&", "||":
+		// 論理演算子は左辺だけで結果が決まれば右辺を評価しない
+		return cg.generateLogicalExpression(infixStmt)
 	case "=":
-		return cg.builder.CreateStore(rhsValue, lhsValue)
+		return cg.generateAssignment(infixStmt)
+	case "+=", "-=", "*=", "/=", "%=":
+		return cg.generateCompoundAssignment(infixStmt)
+	}
+
+	lhsValue := cg.generateExpression(infixStmt.Left)
+	rhsValue := cg.generateExpression(infixStmt.Right)
+	lhsType, rhsType := cg.typeOf(infixStmt.Left), cg.typeOf(infixStmt.Right)
+
+	// ポインタと等しいか比べる0はヌルポインタ
+	if infixStmt.Operator == "==" || infixStmt.Operator == "!=" {
+		if isPointer(lhsValue) && isNullPointerConstant(infixStmt.Right) {
+			rhsValue, rhsType = llvm.ConstNull(lhsValue.Type()), lhsType
+		}
+		if isPointer(rhsValue) && isNullPointerConstant(infixStmt.Left) {
+			lhsValue, lhsType = llvm.ConstNull(rhsValue.Type()), rhsType
+		}
+	}
+
+	return cg.generateBinaryOperation(infixStmt.Operator, lhsValue, lhsType, rhsValue, rhsType)
+}
+
+// generateExpressionAs - 型tの値が必要な場所の式を生成する 0はヌルポインタに変換する
+func (cg *CodeGen) generateExpressionAs(expr ast.Expression, t *ast.Type) llvm.Value {
+	if t.IsPointer() && isNullPointerConstant(expr) {
+		return llvm.ConstNull(cg.llvmType(t))
+	}
+
+	value, ok := cg.convertValue(cg.generateExpression(expr), cg.typeOf(expr), t)
+	if !ok {
+		msg := fmt.Sprintf("%s has an incompatible type", expr.String())
+		panic(msg)
+	}
+	return value
+}
+
+// storeValue - 型fromの値を代入先の型toに変換して格納し、式としての値(格納した値)を返す
+func (cg *CodeGen) storeValue(value llvm.Value, from, to *ast.Type, address llvm.Value, expr ast.Expression) llvm.Value {
+	stored, ok := cg.convertValue(value, from, to)
+	if !ok {
+		msg := fmt.Sprintf("%s has an incompatible type", expr.String())
+		panic(msg)
+	}
+	cg.builder.CreateStore(stored, address)
+
+	return stored
+}
+
+// isNullPointerConstant - ヌルポインタ定数(整数の0)か
+func isNullPointerConstant(expr ast.Expression) bool {
+	switch expr := expr.(type) {
+	case *ast.Number:
+		return expr.Val() == 0
+	case *ast.GroupedExpression:
+		return isNullPointerConstant(expr.Expression)
+	}
+	return false
+}
+
+func isPointer(v llvm.Value) bool {
+	return v.Type().TypeKind() == llvm.PointerTypeKind
+}
+
+// generateBinaryOperation - 評価済みの左辺と右辺に二項演算子を適用する
+// 整数は通常の算術型変換で揃えた型の符号に従って、符号付きか符号なしの命令を選ぶ
+func (cg *CodeGen) generateBinaryOperation(operator string, lhsValue llvm.Value, lhsType *ast.Type, rhsValue llvm.Value, rhsType *ast.Type) llvm.Value {
+	if isPointer(lhsValue) || isPointer(rhsValue) {
+		return cg.generatePointerOperation(operator, lhsValue, lhsType, rhsValue, rhsType)
+	}
+
+	// 構造体は演算できない 剰余、ビット演算、シフトは整数だけ
+	if !lhsType.IsArithmetic() || !rhsType.IsArithmetic() {
+		msg := fmt.Sprintf("invalid operands to %s", operator)
+		panic(msg)
+	}
+	switch operator {
+	case "%", "&", "|", "^", "<<", ">>":
+		if !lhsType.IsInteger() || !rhsType.IsInteger() {
+			msg := fmt.Sprintf("invalid operands to %s", operator)
+			panic(msg)
+		}
+	}
+
+	// シフトは両辺を別々に整数拡張し、右辺は左辺の型に合わせる
+	if operator == "<<" || operator == ">>" {
+		t := lhsType.Promoted()
+		lhsValue = cg.convert(lhsValue, lhsType, t)
+		rhsValue = cg.convert(rhsValue, rhsType, t)
+		if operator == "<<" {
+			return cg.builder.CreateShl(lhsValue, rhsValue, "shl_tmp")
+		}
+		if t.Unsigned {
+			return cg.builder.CreateLShr(lhsValue, rhsValue, "shr_tmp")
+		}
+		return cg.builder.CreateAShr(lhsValue, rhsValue, "shr_tmp")
+	}
+
+	t := ast.CommonType(lhsType, rhsType)
+	lhsValue = cg.convert(lhsValue, lhsType, t)
+	rhsValue = cg.convert(rhsValue, rhsType, t)
+
+	if t.IsFloating() {
+		return cg.generateFloatOperation(operator, lhsValue, rhsValue)
+	}
+
+	if t.Unsigned {
+		switch operator {
+		case "/":
+			return cg.builder.CreateUDiv(lhsValue, rhsValue, "div_tmp")
+		case "%":
+			return cg.builder.CreateURem(lhsValue, rhsValue, "rem_tmp")
+		case "<":
+			return cg.generateComparison(llvm.IntULT, lhsValue, rhsValue)
+		case ">":
+			return cg.generateComparison(llvm.IntUGT, lhsValue, rhsValue)
+		case "<=":
+			return cg.generateComparison(llvm.IntULE, lhsValue, rhsValue)
+		case ">=":
+			return cg.generateComparison(llvm.IntUGE, lhsValue, rhsValue)
+		}
+	}
+
+	switch operator {
 	case "+":
 		return cg.builder.CreateAdd(lhsValue, rhsValue, "add_tmp")
 	case "-":
@@ -208,57 +894,412 @@ func (cg *CodeGen) generateInfixExpression(infixStmt *ast.InfixExpression) llvm.
 		return cg.builder.CreateMul(lhsValue, rhsValue, "mul_tmp")
 	case "/":
 		return cg.builder.CreateSDiv(lhsValue, rhsValue, "div_tmp")
+	case "%":
+		return cg.builder.CreateSRem(lhsValue, rhsValue, "rem_tmp")
+	case "&":
+		return cg.builder.CreateAnd(lhsValue, rhsValue, "and_tmp")
+	case "|":
+		return cg.builder.CreateOr(lhsValue, rhsValue, "or_tmp")
+	case "^":
+		return cg.builder.CreateXor(lhsValue, rhsValue, "xor_tmp")
+	case "==":
+		return cg.generateComparison(llvm.IntEQ, lhsValue, rhsValue)
+	case "!=":
+		return cg.generateComparison(llvm.IntNE, lhsValue, rhsValue)
+	case "<":
+		return cg.generateComparison(llvm.IntSLT, lhsValue, rhsValue)
+	case ">":
+		return cg.generateComparison(llvm.IntSGT, lhsValue, rhsValue)
+	case "<=":
+		return cg.generateComparison(llvm.IntSLE, lhsValue, rhsValue)
+	case ">=":
+		return cg.generateComparison(llvm.IntSGE, lhsValue, rhsValue)
 	default:
 		panic("invalid operator")
 	}
 }
 
-func (cg *CodeGen) generateCallExpression(callExpression *ast.CallExpression) llvm.Value {
-	var argSlice []llvm.Value
-	var argValue llvm.Value
-
-	// 各引数について
-	for _, arg := range callExpression.Arguments {
-		if callExpr, ok := arg.(*ast.CallExpression); ok {
-			argValue = cg.generateCallExpression(callExpr)
-		} else if infixStmt, ok := arg.(*ast.InfixExpression); ok {
-			argValue = cg.generateInfixExpression(infixStmt)
-			if infixStmt.Operator == "=" {
-				ident := infixStmt.Left.(*ast.Identifier)
-				argValue = cg.builder.CreateLoad(*cg.variables[ident.Name()], "arg_val")
-			}
-		} else if ident, ok := arg.(*ast.Identifier); ok {
-			argValue = cg.generateIdentifier(ident)
-		} else if numberStmt, ok := arg.(*ast.Number); ok {
-			argValue = cg.generateNumber(numberStmt.Val())
+// generateFloatOperation - 浮動小数点数の演算 比較は順序付き(NaNとは偽)、!=だけは順序なし(NaNとは真)
+func (cg *CodeGen) generateFloatOperation(operator string, lhsValue, rhsValue llvm.Value) llvm.Value {
+	switch operator {
+	case "+":
+		return cg.builder.CreateFAdd(lhsValue, rhsValue, "fadd_tmp")
+	case "-":
+		return cg.builder.CreateFSub(lhsValue, rhsValue, "fsub_tmp")
+	case "*":
+		return cg.builder.CreateFMul(lhsValue, rhsValue, "fmul_tmp")
+	case "/":
+		return cg.builder.CreateFDiv(lhsValue, rhsValue, "fdiv_tmp")
+	case "==":
+		return cg.generateFloatComparison(llvm.FloatOEQ, lhsValue, rhsValue)
+	case "!=":
+		return cg.generateFloatComparison(llvm.FloatUNE, lhsValue, rhsValue)
+	case "<":
+		return cg.generateFloatComparison(llvm.FloatOLT, lhsValue, rhsValue)
+	case ">":
+		return cg.generateFloatComparison(llvm.FloatOGT, lhsValue, rhsValue)
+	case "<=":
+		return cg.generateFloatComparison(llvm.FloatOLE, lhsValue, rhsValue)
+	case ">=":
+		return cg.generateFloatComparison(llvm.FloatOGE, lhsValue, rhsValue)
+	default:
+		panic("invalid operator")
+	}
+}
+
+// generateAssignment - a = b を生成する 式の値は代入された値
+func (cg *CodeGen) generateAssignment(infixStmt *ast.InfixExpression) llvm.Value {
+	address := cg.generateLvalue(infixStmt.Left)
+	value := cg.generateExpressionAs(infixStmt.Right, cg.objectType(infixStmt.Left))
+	cg.builder.CreateStore(value, address)
+
+	return value
+}
+
+// generateCompoundAssignment - a += b などを生成する 代入先のアドレスは1度だけ評価する
+func (cg *CodeGen) generateCompoundAssignment(infixStmt *ast.InfixExpression) llvm.Value {
+	address := cg.generateLvalue(infixStmt.Left)
+	rhsValue := cg.generateExpression(infixStmt.Right)
+	lhsType, rhsType := cg.objectType(infixStmt.Left), cg.typeOf(infixStmt.Right)
+
+	// 演算は通常の算術型変換で揃えた型で行い、結果を左辺の型に戻す
+	operator := strings.TrimSuffix(infixStmt.Operator, "=")
+	current := cg.loadValue(address)
+	value := cg.generateBinaryOperation(operator, current, lhsType, rhsValue, rhsType)
+
+	return cg.storeValue(value, binaryType(operator, lhsType, rhsType), lhsType, address, infixStmt)
+}
+
+// generatePointerOperation - ポインタと整数の加減算は要素の大きさを単位として進める
+// 整数はlongに変換してから足す
+func (cg *CodeGen) generatePointerOperation(operator string, lhsValue llvm.Value, lhsType *ast.Type, rhsValue llvm.Value, rhsType *ast.Type) llvm.Value {
+	lhsPointer, rhsPointer := isPointer(lhsValue), isPointer(rhsValue)
+
+	switch {
+	case operator == "+" && lhsPointer && rhsType.IsInteger():
+		index := cg.convert(rhsValue, rhsType, ast.Long)
+		return cg.builder.CreateGEP(lhsValue, []llvm.Value{index}, "ptradd_tmp")
+	case operator == "+" && lhsType.IsInteger() && rhsPointer:
+		index := cg.convert(lhsValue, lhsType, ast.Long)
+		return cg.builder.CreateGEP(rhsValue, []llvm.Value{index}, "ptradd_tmp")
+	case operator == "-" && lhsPointer && rhsType.IsInteger():
+		index := cg.builder.CreateNeg(cg.convert(rhsValue, rhsType, ast.Long), "neg_tmp")
+		return cg.builder.CreateGEP(lhsValue, []llvm.Value{index}, "ptrsub_tmp")
+	case lhsPointer && rhsPointer && lhsValue.Type() == rhsValue.Type():
+		switch operator {
+		case "-":
+			// 2つのポインタの間にある要素の数(long)
+			return cg.builder.CreatePtrDiff(lhsValue, rhsValue, "ptrdiff_tmp")
+		case "==":
+			return cg.generateComparison(llvm.IntEQ, lhsValue, rhsValue)
+		case "!=":
+			return cg.generateComparison(llvm.IntNE, lhsValue, rhsValue)
+		case "<":
+			return cg.generateComparison(llvm.IntULT, lhsValue, rhsValue)
+		case ">":
+			return cg.generateComparison(llvm.IntUGT, lhsValue, rhsValue)
+		case "<=":
+			return cg.generateComparison(llvm.IntULE, lhsValue, rhsValue)
+		case ">=":
+			return cg.generateComparison(llvm.IntUGE, lhsValue, rhsValue)
 		}
-		argSlice = append(argSlice, argValue)
 	}
-	return cg.builder.CreateCall(cg.mod.NamedFunction(callExpression.GetCallee()), argSlice, "call_tmp")
+
+	msg := fmt.Sprintf("invalid operands to %s", operator)
+	panic(msg)
 }
 
-func (cg *CodeGen) generateReturnStatement(retStmt *ast.ReturnStatement) llvm.Value {
-	expr := retStmt.ReturnValue
-	var retValue llvm.Value
+// generateIncDec - ++/--を生成する 前置なら更新後の値、後置なら更新前の値を返す
+func (cg *CodeGen) generateIncDec(operand ast.Expression, operator string, isPrefix bool) llvm.Value {
+	address := cg.generateLvalue(operand)
+	current := cg.loadValue(address)
+	t := cg.objectType(operand)
 
-	if infixStmt, ok := expr.(*ast.InfixExpression); ok {
-		retValue = cg.generateInfixExpression(infixStmt)
-	} else if ident, ok := expr.(*ast.Identifier); ok {
-		retValue = cg.generateIdentifier(ident)
-	} else if numberStmt, ok := expr.(*ast.Number); ok {
-		retValue = cg.generateNumber(numberStmt.Val())
+	// ポインタは1要素分進める
+	one := llvm.ConstInt(llvm.Int32Type(), 1, false)
+	binaryOperator := "+"
+	if operator == "--" {
+		binaryOperator = "-"
 	}
+	value := cg.generateBinaryOperation(binaryOperator, current, t, one, ast.Int)
+	value = cg.storeValue(value, binaryType(binaryOperator, t, ast.Int), t, address, operand)
+
+	if isPrefix {
+		return value
+	}
+	return current
+}
 
-	return cg.builder.CreateRet(retValue)
+func (cg *CodeGen) generatePostfixExpression(postfixStmt *ast.PostfixExpression) llvm.Value {
+	return cg.generateIncDec(postfixStmt.Left, postfixStmt.Operator, false)
+}
+
+// generateLvalue - 代入先となる式のアドレスを返す
+func (cg *CodeGen) generateLvalue(expr ast.Expression) llvm.Value {
+	addr := cg.generateAddress(expr)
+
+	// 配列そのものには代入できない
+	if addr.Type().ElementType().TypeKind() == llvm.ArrayTypeKind {
+		msg := fmt.Sprintf("array %s is not assignable", expr.String())
+		panic(msg)
+	}
+	return addr
+}
+
+// generateAddress - 変数や配列の要素のアドレスを返す
+func (cg *CodeGen) generateAddress(expr ast.Expression) llvm.Value {
+	if ident, ok := expr.(*ast.Identifier); ok {
+		return *cg.lookupVariable(ident.Name()).value
+	}
+
+	if indexExpr, ok := expr.(*ast.IndexExpression); ok {
+		return cg.generateIndexAddress(indexExpr)
+	}
+
+	if prefixStmt, ok := expr.(*ast.PrefixExpression); ok && prefixStmt.Operator == "*" {
+		return cg.generateDereference(prefixStmt)
+	}
+
+	if memberExpr, ok := expr.(*ast.MemberExpression); ok {
+		return cg.generateMemberAddress(memberExpr)
+	}
+
+	if groupedStmt, ok := expr.(*ast.GroupedExpression); ok {
+		return cg.generateAddress(groupedStmt.Expression)
+	}
+
+	msg := fmt.Sprintf("%s is not assignable", expr.String())
+	panic(msg)
+}
+
+// generateIndexAddress - a[i]のアドレス 配列は先頭要素へのポインタに変換してから添字を足す
+func (cg *CodeGen) generateIndexAddress(indexExpr *ast.IndexExpression) llvm.Value {
+	base := cg.generateExpression(indexExpr.Left)
+	if base.Type().TypeKind() != llvm.PointerTypeKind {
+		msg := fmt.Sprintf("%s is not an array or a pointer", indexExpr.Left.String())
+		panic(msg)
+	}
+
+	index := cg.generateExpression(indexExpr.Index)
+	if index.Type().TypeKind() != llvm.IntegerTypeKind {
+		msg := fmt.Sprintf("index %s is not an integer", indexExpr.Index.String())
+		panic(msg)
+	}
+	index = cg.convert(index, cg.typeOf(indexExpr.Index), ast.Long)
+
+	return cg.builder.CreateGEP(base, []llvm.Value{index}, "index_tmp")
+}
+
+// generateMemberAddress - s.xはsのアドレス、p->xはpの値から、構造体の中のメンバを指すGEP
+// 共用体のメンバは全て先頭にあるので、共用体へのポインタをメンバの型へのポインタに変換する
+func (cg *CodeGen) generateMemberAddress(memberExpr *ast.MemberExpression) llvm.Value {
+	var base llvm.Value
+	if memberExpr.IsArrow() {
+		base = cg.generateExpression(memberExpr.Left)
+	} else {
+		base = cg.generateAddress(memberExpr.Left)
+	}
+
+	t, index := cg.memberOf(memberExpr)
+	if t.IsUnion() {
+		memberType := llvm.PointerType(cg.llvmType(t.Members[index].Type), 0)
+		return cg.builder.CreateBitCast(base, memberType, "member_tmp")
+	}
+	return cg.builder.CreateStructGEP(base, index, "member_tmp")
+}
+
+// generateDereference - *pのアドレスはpの値そのもの
+func (cg *CodeGen) generateDereference(prefixStmt *ast.PrefixExpression) llvm.Value {
+	pointer := cg.generateExpression(prefixStmt.Right)
+	if !isPointer(pointer) {
+		msg := fmt.Sprintf("%s is not a pointer", prefixStmt.Right.String())
+		panic(msg)
+	}
+	return pointer
+}
+
+// loadValue - アドレスから値を読む 配列は読まずに先頭要素へのポインタにする
+func (cg *CodeGen) loadValue(addr llvm.Value) llvm.Value {
+	if addr.Type().ElementType().TypeKind() == llvm.ArrayTypeKind {
+		zero := llvm.ConstInt(llvm.Int32Type(), 0, false)
+		return cg.builder.CreateGEP(addr, []llvm.Value{zero, zero}, "decay_tmp")
+	}
+	return cg.builder.CreateLoad(addr, "var_tmp")
+}
+
+func (cg *CodeGen) generateLogicalExpression(infixStmt *ast.InfixExpression) llvm.Value {
+	lhsCond := cg.generateCondition(infixStmt.Left)
+	lhsBlock := cg.builder.GetInsertBlock()
+
+	rhsBlock := llvm.AddBasicBlock(*cg.curFunc, "logical_rhs")
+	mergeBlock := llvm.AddBasicBlock(*cg.curFunc, "logical_merge")
+
+	// &&は左辺が偽、||は左辺が真なら右辺を飛ばす
+	var shortCircuit llvm.Value
+	if infixStmt.Operator == "&&" {
+		cg.builder.CreateCondBr(lhsCond, rhsBlock, mergeBlock)
+		shortCircuit = llvm.ConstInt(llvm.Int1Type(), 0, false)
+	} else {
+		cg.builder.CreateCondBr(lhsCond, mergeBlock, rhsBlock)
+		shortCircuit = llvm.ConstInt(llvm.Int1Type(), 1, false)
+	}
+
+	cg.builder.SetInsertPointAtEnd(rhsBlock)
+	rhsCond := cg.generateCondition(infixStmt.Right)
+	rhsBlock = cg.builder.GetInsertBlock() // 右辺の評価中にブロックが変わっている場合がある
+	cg.builder.CreateBr(mergeBlock)
+
+	cg.builder.SetInsertPointAtEnd(mergeBlock)
+	phi := cg.builder.CreatePHI(llvm.Int1Type(), "logical_tmp")
+	phi.AddIncoming([]llvm.Value{shortCircuit, rhsCond}, []llvm.BasicBlock{lhsBlock, rhsBlock})
+
+	return cg.builder.CreateZExt(phi, llvm.Int32Type(), "bool_tmp")
+}
+
+// generateConditionalExpression - cond ? a : b を生成する 選ばれた方の式だけを評価する
+func (cg *CodeGen) generateConditionalExpression(condStmt *ast.ConditionalExpression) llvm.Value {
+	cond := cg.generateCondition(condStmt.Condition)
+
+	trueBlock := llvm.AddBasicBlock(*cg.curFunc, "cond_true")
+	falseBlock := llvm.AddBasicBlock(*cg.curFunc, "cond_false")
+	mergeBlock := llvm.AddBasicBlock(*cg.curFunc, "cond_merge")
+	cg.builder.CreateCondBr(cond, trueBlock, falseBlock)
+
+	// 両方の型が分かるまで、変換を入れるために分岐を閉じずにおく
+	cg.builder.SetInsertPointAtEnd(trueBlock)
+	trueValue := cg.generateExpression(condStmt.Consequence)
+	trueBlock = cg.builder.GetInsertBlock()
+
+	cg.builder.SetInsertPointAtEnd(falseBlock)
+	falseValue := cg.generateExpression(condStmt.Alternative)
+	falseBlock = cg.builder.GetInsertBlock()
+
+	// 算術型は通常の算術型変換で揃える 片方がポインタならもう片方の0はヌルポインタ
+	trueType, falseType := cg.typeOf(condStmt.Consequence), cg.typeOf(condStmt.Alternative)
+	if trueType.IsArithmetic() && falseType.IsArithmetic() {
+		t := ast.CommonType(trueType, falseType)
+		falseValue = cg.convert(falseValue, falseType, t)
+		cg.builder.CreateBr(mergeBlock)
+
+		cg.builder.SetInsertPointAtEnd(trueBlock)
+		trueValue = cg.convert(trueValue, trueType, t)
+	} else {
+		cg.builder.CreateBr(mergeBlock)
+		cg.builder.SetInsertPointAtEnd(trueBlock)
+	}
+	cg.builder.CreateBr(mergeBlock)
+
+	if isPointer(trueValue) && isNullPointerConstant(condStmt.Alternative) {
+		falseValue = llvm.ConstNull(trueValue.Type())
+	} else if isPointer(falseValue) && isNullPointerConstant(condStmt.Consequence) {
+		trueValue = llvm.ConstNull(falseValue.Type())
+	}
+	if trueValue.Type() != falseValue.Type() {
+		msg := fmt.Sprintf("operands of %s have incompatible types", condStmt.String())
+		panic(msg)
+	}
+
+	cg.builder.SetInsertPointAtEnd(mergeBlock)
+	phi := cg.builder.CreatePHI(trueValue.Type(), "cond_tmp")
+	phi.AddIncoming([]llvm.Value{trueValue, falseValue}, []llvm.BasicBlock{trueBlock, falseBlock})
+
+	return phi
+}
+
+func (cg *CodeGen) generatePrefixExpression(prefixStmt *ast.PrefixExpression) llvm.Value {
+	switch prefixStmt.Operator {
+	case "++", "--":
+		return cg.generateIncDec(prefixStmt.Right, prefixStmt.Operator, true)
+	case "&":
+		return cg.generateAddress(prefixStmt.Right)
+	case "*":
+		return cg.loadValue(cg.generateDereference(prefixStmt))
+	}
+
+	value := cg.generateExpression(prefixStmt.Right)
+	t := cg.typeOf(prefixStmt.Right)
+	if (prefixStmt.Operator != "!" && isPointer(value)) || (prefixStmt.Operator == "~" && !t.IsInteger()) ||
+		(!t.IsArithmetic() && !t.IsPointer()) {
+		msg := fmt.Sprintf("invalid operand to %s", prefixStmt.Operator)
+		panic(msg)
+	}
+
+	// -, ~, +の結果は整数拡張した型
+	if prefixStmt.Operator != "!" {
+		value = cg.convert(value, t, t.Promoted())
+	}
+
+	switch {
+	case prefixStmt.Operator == "!" && t.IsFloating():
+		return cg.generateFloatComparison(llvm.FloatOEQ, value, llvm.ConstNull(value.Type()))
+	case prefixStmt.Operator == "-" && t.IsFloating():
+		return cg.builder.CreateFNeg(value, "fneg_tmp")
+	}
+
+	switch prefixStmt.Operator {
+	case "!":
+		return cg.generateComparison(llvm.IntEQ, value, llvm.ConstNull(value.Type()))
+	case "-":
+		return cg.builder.CreateNeg(value, "neg_tmp")
+	case "+":
+		return value
+	case "~":
+		return cg.builder.CreateNot(value, "not_tmp")
+	default:
+		panic("invalid operator")
+	}
+}
+
+// generateComparison - icmpの結果(i1)をintの0/1に拡張して返す
+func (cg *CodeGen) generateComparison(predicate llvm.IntPredicate, lhs, rhs llvm.Value) llvm.Value {
+	cmp := cg.builder.CreateICmp(predicate, lhs, rhs, "cmp_tmp")
+	return cg.builder.CreateZExt(cmp, llvm.Int32Type(), "bool_tmp")
+}
+
+// generateFloatComparison - fcmpの結果(i1)をintの0/1に拡張して返す
+func (cg *CodeGen) generateFloatComparison(predicate llvm.FloatPredicate, lhs, rhs llvm.Value) llvm.Value {
+	cmp := cg.builder.CreateFCmp(predicate, lhs, rhs, "fcmp_tmp")
+	return cg.builder.CreateZExt(cmp, llvm.Int32Type(), "bool_tmp")
+}
+
+func (cg *CodeGen) generateCallExpression(callExpression *ast.CallExpression) llvm.Value {
+	var argSlice []llvm.Value
+
+	function := cg.mod.NamedFunction(callExpression.GetCallee())
+	paramTypes := cg.functions[callExpression.GetCallee()].ParameterTypes
+
+	// 各引数を仮引数の型に変換する 配列は先頭要素へのポインタとして渡す
+	for i, arg := range callExpression.Arguments {
+		argSlice = append(argSlice, cg.generateExpressionAs(arg, paramTypes[i]))
+	}
+
+	// void型の値には名前を付けられない
+	name := "call_tmp"
+	if function.Type().ElementType().ReturnType().TypeKind() == llvm.VoidTypeKind {
+		name = ""
+	}
+	return cg.builder.CreateCall(function, argSlice, name)
+}
+
+func (cg *CodeGen) generateReturnStatement(retStmt *ast.ReturnStatement) llvm.Value {
+	var ret llvm.Value
+	if retStmt.ReturnValue == nil {
+		ret = cg.builder.CreateRetVoid()
+	} else {
+		returnType := cg.functions[cg.curFunc.Name()].ReturnType
+		ret = cg.builder.CreateRet(cg.generateExpressionAs(retStmt.ReturnValue, returnType))
+	}
+	cg.enterDeadBlock("after_ret")
+	return ret
 }
 
 func (cg *CodeGen) generateIdentifier(ident *ast.Identifier) llvm.Value {
-	v := *cg.variables[ident.Name()]
-	return cg.builder.CreateLoad(v, "var_tmp")
+	v := *cg.lookupVariable(ident.Name()).value
+	return cg.loadValue(v)
 }
 
-func (cg *CodeGen) generateNumber(value int) llvm.Value {
-	return llvm.ConstInt(llvm.Int32Type(), uint64(value), false)
+func (cg *CodeGen) generateNumber(number *ast.Number) llvm.Value {
+	return llvm.ConstInt(cg.llvmType(number.Type), uint64(number.Val()), false)
 }
 
 func (cg *CodeGen) linkModule(filename string) {
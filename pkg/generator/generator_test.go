@@ -3,21 +3,1408 @@ package generator
 import (
 	"../lexer"
 	"../parser"
+	"fmt"
 	"io/ioutil"
+	"llvm.org/llvm/bindings/go/llvm"
+	"strings"
 	"testing"
 )
 
 func TestGenerator(t *testing.T) {
 	input, err := readFile("../../test/test.dc")
 	if err != nil {
-		t.Fatalf(err.Error())
+		t.Fatal(err)
 	}
 
-	l := lexer.New(input)
+	generate(t, input)
+}
+
+func TestIfElse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"if (3 < 5) return 1; return 2;", 1},
+		{"if (5 <= 4) return 1; else return 2;", 2},
+		{"if (x == 7) x = 1; else if (x != 7) x = 2; else x = 3; return x;", 1},
+		{"if (x > 7) x = 1; else if (x >= 7) x = 2; else x = 3; return x;", 2},
+		// elseは近い方のifに付く
+		{"if (x > 7) if (x >= 0) return 1; else return 2; return 3;", 3},
+		{"if (x >= 7) if (x < 0) return 1; else return 2; return 3;", 2},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\nint x;\nx = 7;\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestWhile(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"while (i < 10) i++; return i;", 10},
+		{"while (i > 0) i--; return i;", 0},
+		{"while (1) { i++; if (i == 4) break; } return i;", 4},
+		{"while (i < 10) { i++; if (i % 2) continue; s += i; } return s;", 30},
+		// breakは一番内側のループだけを抜ける
+		{"while (i < 3) { i++; while (1) { s++; break; } } return s;", 3},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\nint i = 0, s = 0;\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"for (i = 0; i < 5; i++) s += i; return s;", 10},
+		{"for (i = 0; ; i++) if (i == 3) break; return i;", 3},
+		// continueでも更新式は実行される
+		{"for (i = 0; i < 6; i++) { if (i % 2) continue; s += i; } return s;", 6},
+		{"for (;;) { s += 2; if (s > 5) break; } return s;", 6},
+		{"for (i = 10; i < 5; i++) s++; return s * 100 + i;", 10},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\nint i = 0, s = 0;\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestDoWhile(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		// 本体は条件より先に一度実行される
+		{"do i++; while (i < 0); return i;", 1},
+		{"do i += 2; while (i < 7); return i;", 8},
+		// continueは条件の評価へ進む
+		{"do { i++; if (i == 2) continue; s += i; } while (i < 4); return s;", 8},
+		{"do { if (i == 3) break; i++; } while (1); return i;", 3},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\nint i = 0, s = 0;\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestSwitch(t *testing.T) {
+	input := `int pick(int x) {
+	int s = 0;
+	switch (x) {
+	case 1:
+		s += 1;
+	default:
+		s += 10;
+	case 2:
+		s += 100;
+		break;
+	case 3:
+		s += 1000;
+	}
+	return s;
+}
+
+int main() {
+	return pick(%d);
+}`
+
+	// 途中のdefaultにも飛び込み、breakまで後ろの節へフォールスルーする
+	tests := []struct {
+		x        int
+		expected int
+	}{
+		{1, 111},
+		{2, 100},
+		{3, 1000},
+		{5, 110},
+	}
+
+	for i, tt := range tests {
+		if result := run(t, fmt.Sprintf(input, tt.x)); result != tt.expected {
+			t.Errorf("tests[%d] - pick(%d) returned %d, want %d", i, tt.x, result, tt.expected)
+		}
+	}
+
+	g := generate(t, fmt.Sprintf(input, 0))
+	if !containsInstruction(g.GetModule().NamedFunction("pick"), func(inst llvm.Value) bool {
+		return inst.InstructionOpcode() == llvm.Switch
+	}) {
+		t.Errorf("pick is not lowered to a switch instruction")
+	}
+}
+
+func TestGoto(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"loop: i++; if (i < 5) goto loop; return i;", 5},
+		{"goto skip; i = 100; skip: return i;", 0},
+		// ループの本体の途中にも飛び込める
+		{"goto inner; while (i < 100) { inner: i++; if (i == 2) break; } return i;", 2},
+		{"while (1) { while (1) { i++; goto out; } } out: return i;", 1},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\nint i = 0;\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestShortCircuit(t *testing.T) {
+	input := `int calls;
+
+int touch(int v) {
+	calls++;
+	return v;
+}
+
+int main() {
+	int v = %s;
+	return v * 10 + calls;
+}`
+
+	// 左辺で結果が決まれば右辺のtouchは呼ばれない
+	tests := []struct {
+		expr  string
+		value int
+		calls int
+	}{
+		{"0 && touch(1)", 0, 0},
+		{"1 && touch(2)", 1, 1},
+		{"1 && touch(0)", 0, 1},
+		{"1 || touch(0)", 1, 0},
+		{"0 || touch(3)", 1, 1},
+		{"0 || touch(0)", 0, 1},
+		{"touch(0) && touch(1)", 0, 1},
+		{"!touch(0)", 1, 1},
+		{"!5", 0, 0},
+	}
+
+	for i, tt := range tests {
+		expected := tt.value*10 + tt.calls
+		if result := run(t, fmt.Sprintf(input, tt.expr)); result != expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.expr, result, expected)
+		}
+	}
+}
+
+func TestUnaryOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"-x", -7},
+		{"+x", 7},
+		{"~x", -8},
+		{"- -x", 7},
+		{"-~x", 8},
+		{"~0", -1},
+		{"-x * 2", -14},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\nint x;\nx = 7;\nreturn " + tt.input + ";\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"x % 3", 1},
+		// 剰余の符号は割られる数に従う
+		{"-x % 3", -1},
+		{"x % -3", 1},
+		{"x & 3", 3},
+		{"x | 8", 15},
+		{"x ^ 5", 2},
+		{"x << 2", 28},
+		{"-16 >> 2", -4},
+		{"1 + 2 << 1", 6},
+		{"x & 3 == 3", 1},
+		{"x | 8 ^ 12 & 10", 7},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\nint x;\nx = 7;\nreturn " + tt.input + ";\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestIncDecAndCompoundAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		// 後置は変更前の値、前置は変更後の値になる
+		{"y = x++; return y * 10 + x;", 56},
+		{"y = ++x; return y * 10 + x;", 66},
+		{"y = x--; return y * 10 + x;", 54},
+		{"y = --x; return y * 10 + x;", 44},
+		{"x += 3; return x;", 8},
+		{"x -= 3; return x;", 2},
+		{"x *= 3; return x;", 15},
+		{"x /= 2; return x;", 2},
+		{"x %= 3; return x;", 2},
+		{"x <<= 2; return x;", 20},
+		{"x >>= 1; return x;", 2},
+		{"x &= 6; return x;", 4},
+		{"x |= 2; return x;", 7},
+		{"x ^= 1; return x;", 4},
+		{"y = (x += 2) * 2; return y * 10 + x;", 147},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\nint x = 5, y = 0;\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestConditional(t *testing.T) {
+	input := `int calls;
+
+int touch(int v) {
+	calls++;
+	return v;
+}
+
+int main() {
+	int v = %s;
+	return v * 10 + calls;
+}`
+
+	// 選ばれなかった側のtouchは呼ばれない
+	tests := []struct {
+		expr  string
+		value int
+		calls int
+	}{
+		{"1 ? touch(3) : touch(4)", 3, 1},
+		{"0 ? touch(3) : touch(4)", 4, 1},
+		{"1 ? 2 : touch(9)", 2, 0},
+		{"touch(0) ? 5 : 6", 6, 1},
+		// 右結合で、後ろの条件は前の条件が偽のときだけ評価する
+		{"0 ? 1 : 0 ? 2 : 3", 3, 0},
+		{"1 ? 1 : touch(0) ? 2 : 3", 1, 0},
+	}
+
+	for i, tt := range tests {
+		expected := tt.value*10 + tt.calls
+		if result := run(t, fmt.Sprintf(input, tt.expr)); result != expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.expr, result, expected)
+		}
+	}
+}
+
+func TestParentheses(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"((4))", 4},
+		{"10 - (3 - 2)", 9},
+		{"10 - 3 - 2", 5},
+		{"-(2 + 3) * 2", -10},
+		{"(x - 1) / (x - 4)", 2},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\nint x;\nx = 7;\nreturn " + tt.input + ";\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestAssignmentValue(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		// 右結合で、代入式の値は代入した値になる
+		{"a = b = 3; return a * 10 + b;", 33},
+		{"a = b = c = 4; return a + b + c;", 12},
+		{"a = (b = 2) + 1; return a * 10 + b;", 32},
+		{"return a = 5;", 5},
+		{"if ((a = 0)) return 1; return a + 2;", 2},
+		{"while ((c = c + 1) < 3) b += c; return b;", 3},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\nint a = 1, b = 0, c = 0;\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestExpressionContexts(t *testing.T) {
+	// どの種類の式も、式が書ける全ての場所でコード生成できること
+	expressions := []string{
+		"7",
+		"a",
+		"a + b * 2",
+		"a % b << 1",
+		"a < b",
+		"-a",
+		"!a",
+		"~a",
+		"a++",
+		"--b",
+		"(a - b)",
+		"a ? b : 3",
+		"a && f(b)",
+		"a || b",
+		"f(a)",
+		"f(f(b) + 1)",
+		"a = 4",
+		"a = b = f(a)",
+		"b += a",
+	}
+
+	contexts := []string{
+		"%s;",
+		"return %s;",
+		"f(%s);",
+		"a = %s;",
+		"a = 1 + (%s);",
+		"a = (%s) ? 1 : 2;",
+		"if (%s) a = 1; else a = 2;",
+		"while (%s) break;",
+		"do a = 1; while (%s);",
+		"for (%s; %s; %s) break;",
+		"switch (%s) { case 1: break; }",
+		"int c = %s, d = c;",
+	}
+
+	for _, expr := range expressions {
+		for _, context := range contexts {
+			input := fmt.Sprintf(`int f(int x);
+
+int main() {
+	int a;
+	int b;
+	a = 1;
+	b = 2;
+	%s
+	return 0;
+}`, strings.Replace(context, "%s", expr, -1))
+
+			generate(t, input)
+		}
+	}
+}
+
+func TestBlockScopes(t *testing.T) {
+	input := `int main(int n) {
+	int a;
+	a = n;
+	while (a < 10) {
+		int a;
+		a = 1;
+		n = n + a;
+		break;
+	}
+	int b;
+	b = a;
+	{
+		int b;
+		b = 2;
+		switch (b) {
+		case 2:
+			int n;
+			n = b;
+		}
+	}
+	return a + b + n;
+}`
+
+	g := generate(t, input)
+
+	// 宣言の位置に関わらずallocaは先頭ブロックにまとめる
+	entry := g.GetModule().NamedFunction("main").EntryBasicBlock()
+	allocas := 0
+	for inst := entry.FirstInstruction(); !inst.IsNil(); inst = llvm.NextInstruction(inst) {
+		if !inst.IsAAllocaInst().IsNil() {
+			allocas++
+		}
+	}
+	if allocas != 6 {
+		t.Errorf("entry block does not contain %d allocas. got=%d", 6, allocas)
+	}
+
+	// 内側の宣言はブロックの中でだけ外側の変数を隠す
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"int a = 1;\n{ int a = 2; a++; }\nreturn a;", 1},
+		{"int a = 1;\n{ a = 2; int a = 5; a++; }\nreturn a;", 2},
+		{"int a = 1;\n{ int b = a + 1; { int a = b * 10; b = a; } a += b; }\nreturn a;", 21},
+		{"int s = 0, i = 3;\nwhile (i < 5) { int i = 10; s += i; break; }\nreturn s + i;", 13},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestForDeclaration(t *testing.T) {
+	input := `int main() {
+	int i = 5;
+	int sum = 0;
+	for (int i = 0, j = 10; i < j; i++) {
+		int i = 1;
+		sum += i * j;
+	}
+	for (unsigned char i = 250; i; i++)
+		sum += i;
+	for (int k = 0; ; k++)
+		if (k > 3)
+			break;
+	return sum + i;
+}`
+
+	// 本体の宣言は初期化節の変数を、初期化節の宣言は外側の変数を隠す
+	if result := run(t, input); result != 1620 {
+		t.Errorf("main returned %d, want %d", result, 1620)
+	}
+
+	// ループで宣言した変数はループの後では見えない
+	if !rejects("int main() {\nfor (int k = 0; k < 3; k++) ;\nreturn k;\n}") {
+		t.Fatal("k should not be visible after the loop")
+	}
+}
+
+func TestSwitchCaseValues(t *testing.T) {
+	tests := []struct {
+		input    string
+		rejected bool
+	}{
+		{"int x;\nswitch (x) { case 1: case 4294967297L: break; }", true},
+		{"unsigned x;\nswitch (x) { case -1: case 4294967295u: break; }", true},
+		{"int x;\nswitch (x) { case 0: case -4294967296L: break; }", true},
+		{"long x;\nswitch (x) { case 1: case 4294967297L: break; }", false},
+		{"char x;\nswitch (x) { case 1: case 257: break; }", false},
+		{"unsigned long x;\nswitch (x) { case -1: case 4294967295u: break; }", false},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\n" + tt.input + "\nreturn 0;\n}"
+		if rejected := rejects(input); rejected != tt.rejected {
+			t.Fatalf("tests[%d] - %q rejected=%t, want %t", i, tt.input, rejected, tt.rejected)
+		}
+		if tt.rejected {
+			continue
+		}
+
+		generate(t, input)
+	}
+}
+
+func TestInitializers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"int a = 2;\nreturn a;", 2},
+		// 初期化子は前の宣言子の変数を使える
+		{"int a = 2, b = a * 3, c;\nc = b + 1;\nreturn a * 100 + b * 10 + c;", 267},
+		{"int a = 1;\nint b = a++, c = a;\nreturn b * 10 + c;", 12},
+		{"int i = 0, s = 0;\nwhile (i < 3) { int t = i * 2; s += t; i++; }\nreturn s;", 6},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestGlobalVariables(t *testing.T) {
+	input := `int counter = 3, total;
+
+int count(int n) {
+	counter += n;
+	return counter;
+}
+
+int main() {
+	int counter = count(2);
+	total = counter;
+	return total;
+}`
+
+	g := generate(t, input)
+
+	// countは大域変数を、mainは同名の局所変数を読み書きする
+	if result := run(t, input); result != 5 {
+		t.Errorf("main returned %d, want %d", result, 5)
+	}
+
+	tests := []struct {
+		name  string
+		value int64
+	}{
+		{"counter", 3},
+		{"total", 0},
+	}
+	for _, tt := range tests {
+		global := g.GetModule().NamedGlobal(tt.name)
+		if global.IsNil() {
+			t.Fatalf("global %s is not defined", tt.name)
+		}
+		if v := global.Initializer().SExtValue(); v != tt.value {
+			t.Errorf("global %s is not initialized to %d. got=%d", tt.name, tt.value, v)
+		}
+	}
+}
+
+func TestVoidFunctions(t *testing.T) {
+	input := `void show(int n) {
+	if (n < 0)
+		return;
+	printnum(n);
+}
+
+void twice(int n) {
+	show(n);
+	show(n);
+}
+
+int main() {
+	int i;
+	for (i = 0; i < 3; twice(i++))
+		show(i);
+	(show(i));
+	i ? show(1) : twice(2);
+	i ? (show(1)) : i > 1 ? show(2) : twice(3);
+}`
+
+	generate(t, input)
+
+	// 値のないreturnと末尾で呼び出し元に戻る mainは末尾で0を返す
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"add(3);\nadd(-1);\nadd(4);\nreturn total;", 7},
+		{"add(2);\nadd(total);", 0},
+		{"if (total == 0) add(5);\nreturn total;", 5},
+	}
+
+	for i, tt := range tests {
+		input := "int total;\nvoid add(int n) {\nif (n < 0)\nreturn;\ntotal += n;\n}\nint main() {\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestVoidValueUsed(t *testing.T) {
+	tests := []string{
+		"int a = show(1);",
+		"return show(1);",
+		"printnum(show(1));",
+		"if (show(1)) return 1;",
+		"show(1) + 1;",
+		"(show(1)) + 1;",
+		"1 ? show(1) : 2;",
+		"1 ? 2 : (show(1));",
+		"int a = 1 ? show(1) : show(2);",
+	}
+
+	for i, tt := range tests {
+		input := "void show(int n);\nint main() {\n" + tt + "\nreturn 0;\n}"
+		expectPanic(t, i, input)
+	}
+}
+
+func TestArrays(t *testing.T) {
+	input := `int table[4];
+
+int sum(int v[], int n);
+
+int sum(int values[], int n) {
+	int i, s = 0;
+	for (i = 0; i < n; i++)
+		s += values[i];
+	return s;
+}
+
+int trace(int m[][3]) {
+	return m[0][0] + m[1][1] + m[2][2];
+}
+
+int main() {
+	int a[5], m[3][3];
+	int i;
+	for (i = 0; i < 5; ++i) {
+		a[i] = i * i;
+		table[i % 4] += a[i]--;
+	}
+	m[1][1] = sum(a, 5);
+	printnum(trace(m) + sum(table, 4) + sum(m[2], 3));
+	return 0;
+}`
+
+	generate(t, input)
+
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"int a[3];\na[0] = 1;\na[1] = 2;\na[2] = a[0] + a[1];\nreturn a[2];", 3},
+		{"int m[2][3];\nm[1][2] = 7;\nm[0][1] = 2;\nreturn m[1][2] * m[0][1];", 14},
+		// 配列は先頭要素へのポインタとして渡り、呼び出し先の書き込みが見える
+		{"int a[4], i;\nfor (i = 0; i < 4; i++)\na[i] = i * i;\nreturn sum(a, 4);", 14},
+		{"int a[2];\nfill(a, 2, 6);\nreturn a[0] + a[1];", 12},
+		{"int m[2][2];\nfill(m[1], 2, 3);\nm[0][0] = 1;\nreturn m[0][0] + m[1][0] + m[1][1];", 7},
+		{"int a[2];\na[0] = 5;\na[1] = a[0]++;\nreturn a[0] * 10 + a[1];", 65},
+	}
+
+	for i, tt := range tests {
+		input := `int sum(int v[], int n) {
+	int i, s = 0;
+	for (i = 0; i < n; i++)
+		s += v[i];
+	return s;
+}
+
+void fill(int v[], int n, int x) {
+	while (n--)
+		v[n] = x;
+}
+
+int main() {
+` + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestInvalidArrayUse(t *testing.T) {
+	tests := []string{
+		"a = b;",
+		"a++;",
+		"m[0] = a;",
+		"x[0] = 1;",
+		"f(x);",
+		"f(m);",
+		"printnum(a);",
+	}
+
+	for i, tt := range tests {
+		input := "int f(int v[]);\nint main() {\nint a[2], b[2], m[2][2], x;\n" + tt + "\nreturn 0;\n}"
+		expectPanic(t, i, input)
+	}
+}
+
+func TestPointers(t *testing.T) {
+	input := `int *last = 0;
+
+void swap(int *a, int *b) {
+	int t = *a;
+	*a = *b;
+	*b = t;
+}
+
+int *max(int *p, int n) {
+	int *m = p, *end = p + n;
+	for (; p < end; p++)
+		if (*p > *m)
+			m = p;
+	last = m;
+	return m;
+}
+
+int main() {
+	int x = 1, y = 2;
+	int v[4], *p = v, **pp = &p;
+	swap(&x, &y);
+	*p = x;
+	p[1] = y;
+	*(p + 2) = 3;
+	*(v + 3) = **pp + 1;
+	printnum(max(v, 4) - v);
+	printnum(*max(&v[1], 3));
+	if (last != 0 && !(last == p))
+		p = last ? last : 0;
+	return *&x;
+}`
+
+	generate(t, input)
+
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"int x = 1, y = 2;\nswap(&x, &y);\nreturn x * 10 + y;", 21},
+		{"int x = 3, *p = &x, **pp = &p;\n**pp += 1;\nreturn x;", 4},
+		{"int v[3], *p = v;\n*p = 4;\np[1] = 5;\n*(p + 2) = 6;\nreturn v[0] + v[1] * 10 + v[2] * 100;", 654},
+		// ポインタの加減算と差は要素単位
+		{"int v[4], *p = &v[3];\nreturn p - v;", 3},
+		{"int v[4], *p = v;\nv[2] = 9;\np++;\np += 1;\nreturn *p;", 9},
+		{"int v[2], *p = v, *q = v + 1;\nreturn (p < q) * 10 + (p == q);", 10},
+		{"int *p = 0;\nreturn !p;", 1},
+	}
+
+	for i, tt := range tests {
+		input := "void swap(int *a, int *b) {\nint t = *a;\n*a = *b;\n*b = t;\n}\nint main() {\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestInvalidPointerOperation(t *testing.T) {
+	tests := []string{
+		"p = x;",
+		"x = p;",
+		"p = 1;",
+		"x = *x;",
+		"p * 2;",
+		"p + p;",
+		"x - p;",
+		"-p;",
+		"~p;",
+		"p == pp;",
+		"x += p;",
+		"p *= 2;",
+		"x ? p : x;",
+		"return p;",
+		"pp = &x;",
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\nint x, *p, **pp;\n" + tt + "\nreturn 0;\n}"
+		expectPanic(t, i, input)
+	}
+}
+
+func TestChars(t *testing.T) {
+	input := `int putchar(int c);
+
+char newline = '\n';
+
+void puts(char *s) {
+	while (*s)
+		putchar(*s++);
+	putchar(newline);
+}
+
+int length(char *s) {
+	char *p = s;
+	while (*p != '\0')
+		p++;
+	return p - s;
+}
+
+int main() {
+	char buf[4], *p = buf, c = 'a';
+	int n = 300;
+	buf[0] = c;
+	buf[1] = c + 1;
+	*(p + 2) = n;
+	buf[3] = 0;
+	c += 2;
+	c++;
+	puts(buf);
+	puts("hello, " "world");
+	printnum(length("abc") + buf[1] - 'a');
+	return c;
+}`
+
+	generate(t, input)
+
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"return 'a';", 97},
+		{"return '\\n' + '\\0';", 10},
+		{"return length(\"hello, \" \"world\");", 12},
+		{"char *s = \"abc\";\nreturn s[1];", 98},
+		{"char c = 'a';\nc += 2;\nreturn c;", 99},
+		// charは符号付きで、代入で下位8ビットに切り詰める
+		{"char c = 300;\nreturn c;", 44},
+		{"char c = 127;\nc++;\nreturn c;", -128},
+		{"char buf[3];\nbuf[0] = 'h';\nbuf[1] = 'i';\nbuf[2] = 0;\nreturn length(buf);", 2},
+	}
+
+	for i, tt := range tests {
+		input := "int length(char *s) {\nchar *p = s;\nwhile (*p)\np++;\nreturn p - s;\n}\nint main() {\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestIntegerTypes(t *testing.T) {
+	input := `unsigned long long big = 1ULL << 40;
+short table[4];
+
+long sum(short *p, int n) {
+	long total = 0;
+	int i;
+	for (i = 0; i < n; i++)
+		total += p[i];
+	return total;
+}
+
+unsigned char low(unsigned x) {
+	return x;
+}
+
+int main() {
+	signed char c = -1;
+	unsigned char u = 255;
+	unsigned short us = u + 1;
+	long l = c;
+	unsigned long ul = c;
+	int i = 0;
+	table[i++] = c;
+	table[i++] = u;
+	table[i] = -u;
+	printnum(sum(table, 3));
+	printnum(c < u);
+	printnum(l > 4294967295u);
+	printnum(big / ul % 7);
+	printnum(low(511) + us);
+	switch (u) {
+	case 255:
+		u -= 256;
+		break;
+	}
+	return u == 255 ? c : ul;
+}`
+
+	generate(t, input)
+
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"unsigned char u = 255;\nu++;\nreturn u;", 0},
+		{"short s = 65537;\nreturn s;", 1},
+		{"unsigned char a = 200, b = 100;\nreturn a + b;", 300},
+		{"long l = 1L << 40;\nreturn l >> 38;", 4},
+		{"unsigned long long big = 18446744073709551615ULL;\nreturn big % 1000;", 615},
+		// 通常の算術変換では、intとunsignedの比較は符号なしで行う
+		{"int i = -1;\nunsigned u = 1;\nreturn i < u;", 0},
+		{"long l = -1;\nunsigned u = 1;\nreturn l < u;", 1},
+		{"signed char c = -1;\nunsigned u = c;\nreturn u == 4294967295u;", 1},
+		{"unsigned u = 0;\nreturn u - 1 > 0;", 1},
+		{"unsigned u = 4294967295u;\nreturn u / 2 == 2147483647;", 1},
+		{"int i = -8;\nunsigned u = i;\nreturn (u >> 28) * 10 + (i >> 28);", 149},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestSignedness(t *testing.T) {
+	input := `int sdiv(int a, int b) { return a / b; }
+unsigned udiv(unsigned a, unsigned b) { return a / b; }
+unsigned mixed(int a, unsigned b) { return a / b; }
+long wide(long a, unsigned b) { return a / b; }
+unsigned long urem(long a, unsigned long b) { return a % b; }
+int ashr(int a, unsigned n) { return a >> n; }
+unsigned lshr(unsigned a, int n) { return a >> n; }
+int promoted(unsigned char a, int n) { return a >> n; }
+long zext(unsigned a) { return a; }
+long sext(int a) { return a; }
+int slt(long a, unsigned b) { return a < b; }
+int ult(int a, unsigned b) { return a < b; }
+int uge(unsigned short a, unsigned long b) { return a >= b; }
+int sgt(unsigned short a, int b) { return a > b; }`
+
+	g := generate(t, input)
+
+	opcodes := []struct {
+		function string
+		expected llvm.Opcode
+	}{
+		{"sdiv", llvm.SDiv},
+		{"udiv", llvm.UDiv},
+		{"mixed", llvm.UDiv},
+		{"wide", llvm.SDiv},
+		{"urem", llvm.URem},
+		{"ashr", llvm.AShr},
+		{"lshr", llvm.LShr},
+		{"promoted", llvm.AShr},
+		{"zext", llvm.ZExt},
+		{"sext", llvm.SExt},
+	}
+
+	for _, tt := range opcodes {
+		if !containsInstruction(g.GetModule().NamedFunction(tt.function), func(inst llvm.Value) bool {
+			return inst.InstructionOpcode() == tt.expected
+		}) {
+			t.Errorf("%s does not contain the expected instruction", tt.function)
+		}
+	}
+
+	predicates := []struct {
+		function string
+		expected llvm.IntPredicate
+	}{
+		{"slt", llvm.IntSLT},
+		{"ult", llvm.IntULT},
+		{"uge", llvm.IntUGE},
+		{"sgt", llvm.IntSGT},
+	}
+
+	for _, tt := range predicates {
+		if !containsInstruction(g.GetModule().NamedFunction(tt.function), func(inst llvm.Value) bool {
+			return inst.InstructionOpcode() == llvm.ICmp && inst.IntPredicate() == tt.expected
+		}) {
+			t.Errorf("%s does not contain the expected comparison", tt.function)
+		}
+	}
+}
+
+func TestFloatingPoint(t *testing.T) {
+	input := `double add(double a, double b) { return a + b; }
+double mul(double a, int b) { return a * b; }
+float quotient(float a, float b) { return a / b; }
+int less(double a, double b) { return a < b; }
+int truth(double d) { if (d) return 1; return !d; }
+double negate(double d) { return -d; }
+double uconv(unsigned u) { return u; }
+int trunc(double d) { return d; }
+unsigned utrunc(double d) { return d; }
+double widen(float f) { return f; }
+float narrow(double d) { return d; }
+double select(int c, float f, double d) { return c ? f : d; }
+int main() {
+	float f = 1;
+	f += 0.5;
+	f++;
+	printdouble(f);
+	return 0;
+}`
+
+	g := generate(t, input)
+
+	tests := []struct {
+		function string
+		expected llvm.Opcode
+	}{
+		{"add", llvm.FAdd},
+		{"mul", llvm.SIToFP},
+		{"mul", llvm.FMul},
+		{"quotient", llvm.FDiv},
+		{"less", llvm.FCmp},
+		{"truth", llvm.FCmp},
+		{"negate", llvm.FNeg},
+		{"uconv", llvm.UIToFP},
+		{"trunc", llvm.FPToSI},
+		{"utrunc", llvm.FPToUI},
+		{"widen", llvm.FPExt},
+		{"narrow", llvm.FPTrunc},
+		{"select", llvm.FPExt},
+		{"main", llvm.FPTrunc},
+		{"main", llvm.FPExt},
+	}
+
+	for _, tt := range tests {
+		if !containsInstruction(g.GetModule().NamedFunction(tt.function), func(inst llvm.Value) bool {
+			return inst.InstructionOpcode() == tt.expected
+		}) {
+			t.Errorf("%s does not contain the expected instruction", tt.function)
+		}
+	}
+
+	values := []struct {
+		input    string
+		expected int
+	}{
+		{"double d = 7;\nreturn d / 2 * 10;", 35},
+		{"return 1.5 + 1.75;", 3},
+		// 整数への変換は0の方向へ切り捨てる
+		{"double d = -2.7;\nreturn d;", -2},
+		{"float f = 0.5;\nf += 0.25;\nreturn f * 4;", 3},
+		{"float f = 1;\nf++;\nreturn f;", 2},
+		{"float f = 0.1;\nreturn f == 0.1;", 0},
+		{"double d = 0.5;\nif (d)\nreturn 1;\nreturn 2;", 1},
+		{"double d = 0;\nreturn !d;", 1},
+		{"unsigned u = 4294967295u;\ndouble d = u;\nreturn d > 0;", 1},
+		{"int i = 3;\nreturn i / 2 + i / 2.0 * 10;", 16},
+	}
+
+	for i, tt := range values {
+		input := "int main() {\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("values[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestInvalidFloatingOperation(t *testing.T) {
+	tests := []string{
+		"d % 2;",
+		"d & x;",
+		"d << 1;",
+		"x >> d;",
+		"~d;",
+		"x %= d;",
+		"p + d;",
+		"a[d];",
+		"p = d;",
+		"d = p;",
+		"switch (d) {\ndefault:\nbreak;\n}",
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\nint x, *p, a[2];\ndouble d;\n" + tt + "\nreturn 0;\n}"
+		expectPanic(t, i, input)
+	}
+}
+
+func TestStructs(t *testing.T) {
+	input := `struct point { int x; int y; };
+struct segment { struct point from, to; char label[4]; };
+struct node { int value; struct node *next; };
+struct segment origin;
+int length(struct segment *s) { return s->to.x - s->from.x + (s->to.y - s->from.y); }
+int sum(struct node *n) {
+	int total = 0;
+	while (n) {
+		total += n->value;
+		n = n->next;
+	}
+	return total;
+}
+int main() {
+	struct segment s, copy;
+	struct node a, b, *list;
+	struct point points[2];
+	s.from.x = 1;
+	s.from.y = 2;
+	s.to = s.from;
+	s.to.x += 10;
+	s.label[0] = 'a';
+	copy = s;
+	origin = copy;
+	points[1] = origin.to;
+	a.value = 1;
+	b.value = points[1].x;
+	a.next = &b;
+	b.next = 0;
+	list = &a;
+	list->next->value++;
+	return length(&copy) + sum(list);
+}`
+
+	g := generate(t, input)
+
+	// 代入はメンバをまとめてコピーし、元の構造体とは別の値になる
+	if result := run(t, input); result != 23 {
+		t.Errorf("main returned %d, want %d", result, 23)
+	}
+
+	if !containsInstruction(g.GetModule().NamedFunction("length"), func(inst llvm.Value) bool {
+		return inst.InstructionOpcode() == llvm.GetElementPtr
+	}) {
+		t.Errorf("length does not access members with getelementptr")
+	}
+
+	// 構造体の代入は構造体の値をまとめて読み書きする
+	for _, opcode := range []llvm.Opcode{llvm.Load, llvm.Store} {
+		if !containsInstruction(g.GetModule().NamedFunction("main"), func(inst llvm.Value) bool {
+			value := inst
+			if opcode == llvm.Store {
+				value = inst.Operand(0)
+			}
+			return inst.InstructionOpcode() == opcode && value.Type().TypeKind() == llvm.StructTypeKind
+		}) {
+			t.Errorf("main does not copy structs as a whole")
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	input := `struct mixed { char c; double d; short s; };
+struct nested { char c; struct mixed m; int tail[3]; };
+struct pointers { int *p; char c; struct pointers *next; };
+struct small { char a; char b; };
+union number { char c; int i; double d; };
+union bytes { char c[5]; int i; };
+union pair { char a; short b; };
+union text { char s[3]; };
+struct tagged { char kind; union bytes value; short tail; };
+struct node { char tag; struct node *next; double value; int data[3]; };
+struct variant { int kind; union number value; };
+struct mixed a;
+struct nested b;
+struct pointers c;
+struct small d;
+union number e;
+union bytes f;
+union pair g;
+union text h;
+struct tagged i;
+struct node j;
+struct variant k;`
+
+	g := generate(t, input)
+
+	if g.GetModule().Target() != llvm.DefaultTargetTriple() {
+		t.Errorf("target is not the host %q. got=%q", llvm.DefaultTargetTriple(), g.GetModule().Target())
+	}
+	if _, layout := nativeTarget(); g.GetModule().DataLayout() != layout {
+		t.Errorf("data layout is not the host's %q. got=%q", layout, g.GetModule().DataLayout())
+	}
+
+	// 大きさ、境界、メンバの位置はホストのCコンパイラ(x86-64 Linux)と同じ
+	tests := []struct {
+		global  string
+		size    int
+		align   int
+		offsets []int
+	}{
+		{"a", 24, 8, []int{0, 8, 16}},
+		{"b", 48, 8, []int{0, 8, 32}},
+		{"c", 24, 8, []int{0, 8, 16}},
+		{"d", 2, 1, []int{0, 1}},
+		{"e", 8, 8, nil},
+		{"f", 8, 4, nil},
+		{"g", 2, 2, nil},
+		{"h", 3, 1, nil},
+		{"i", 16, 4, []int{0, 4, 12}},
+		{"j", 40, 8, []int{0, 8, 16, 24}},
+		{"k", 16, 8, []int{0, 8}},
+	}
+
+	td := llvm.NewTargetData(g.GetModule().DataLayout())
+	defer td.Dispose()
+
+	for i, tt := range tests {
+		st := g.GetModule().NamedGlobal(tt.global).Type().ElementType()
+		if size, align := int(td.TypeAllocSize(st)), td.ABITypeAlignment(st); size != tt.size || align != tt.align {
+			t.Errorf("tests[%d] - %s is not of size %d aligned to %d. got=%d aligned to %d", i, tt.global, tt.size, tt.align, size, align)
+		}
+		for j, offset := range tt.offsets {
+			if got := int(td.ElementOffset(st, j)); got != offset {
+				t.Errorf("tests[%d] - member %d of %s is not at %d. got=%d", i, j, tt.global, offset, got)
+			}
+		}
+	}
+}
+
+func TestInvalidStructUse(t *testing.T) {
+	tests := []string{
+		"p.z;",
+		"q->z;",
+		"q.x;",
+		"p->x;",
+		"x.y;",
+		"p = o;",
+		"q = &o;",
+		"x = p;",
+		"q = p;",
+		"p + 1;",
+		"p == p;",
+		"p += p;",
+		"p++;",
+		"-p;",
+		"!p;",
+		"if (p) x = 1;",
+		"x = p ? 1 : 2;",
+		"(x ? p : p).x = 1;",
+	}
+
+	for i, tt := range tests {
+		input := "struct point { int x, y; };\nstruct other { int x; };\nint main() {\nstruct point p, *q;\nstruct other o;\nint x;\n" + tt + "\nreturn 0;\n}"
+		expectPanic(t, i, input)
+	}
+}
+
+func TestUnions(t *testing.T) {
+	input := `union number { char c; int i; double d; };
+struct tagged { int kind; union number value; };
+union number shared;
+double get(struct tagged *t) {
+	if (t->kind)
+		return t->value.d;
+	return t->value.i;
+}
+int main() {
+	struct tagged a, b;
+	union number n, *p;
+	a.kind = 0;
+	a.value.i = 42;
+	b = a;
+	n = b.value;
+	shared = n;
+	p = &shared;
+	p->d = 1.5;
+	p->c++;
+	return get(&b) + n.c;
+}`
+
+	g := generate(t, input)
+
+	// 共用体のメンバは共用体へのポインタを変換して読み書きする
+	if !containsInstruction(g.GetModule().NamedFunction("get"), func(inst llvm.Value) bool {
+		return inst.InstructionOpcode() == llvm.BitCast
+	}) {
+		t.Errorf("get does not access union members with bitcast")
+	}
+
+	if !containsInstruction(g.GetModule().NamedFunction("main"), func(inst llvm.Value) bool {
+		return inst.InstructionOpcode() == llvm.Store && inst.Operand(0).Type().TypeKind() == llvm.StructTypeKind
+	}) {
+		t.Errorf("main does not copy unions as a whole")
+	}
+
+	// メンバは同じ記憶域を共有する
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"n.d = 2.5;\nreturn n.d * 2;", 5},
+		{"n.i = 0;\nn.c = 1;\nreturn n.i != 0;", 1},
+		{"n.i = 7;\nm = n;\nn.i = 1;\nreturn m.i;", 7},
+		{"t.value.i = 3;\nt.kind = 9;\nreturn t.value.i + t.kind;", 12},
+		{"union number *p = &n;\np->i = 4;\nreturn n.i;", 4},
+	}
+
+	for i, tt := range tests {
+		input := "union number { char c; int i; double d; };\nstruct tagged { int kind; union number value; };\nint main() {\nunion number n, m;\nstruct tagged t;\n" + tt.input + "\n}"
+		if result := run(t, input); result != tt.expected {
+			t.Errorf("tests[%d] - %q returned %d, want %d", i, tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestInvalidUnionUse(t *testing.T) {
+	tests := []string{
+		"u.z;",
+		"q->z;",
+		"q.i;",
+		"u->i;",
+		"u = s;",
+		"q = &s;",
+		"x = u;",
+		"u + 1;",
+		"u == u;",
+		"u++;",
+		"-u;",
+		"!u;",
+		"if (u) x = 1;",
+		"x = u ? 1 : 2;",
+	}
+
+	for i, tt := range tests {
+		input := "union number { int i; double d; };\nstruct other { int i; };\nint main() {\nunion number u, *q;\nstruct other s;\nint x;\n" + tt + "\nreturn 0;\n}"
+		expectPanic(t, i, input)
+	}
+}
+
+// generate - srcからモジュールを生成し、正しいモジュールでなければ失敗にする
+func generate(t *testing.T, src string) *CodeGen {
+	t.Helper()
+	l := lexer.New(src)
+	p := parser.New(l)
+	tu := p.Parse()
+	g := New()
+	g.Generate(tu, "test", "")
+
+	if err := llvm.VerifyModule(g.GetModule(), llvm.ReturnStatusAction); err != nil {
+		t.Fatalf("invalid module for %q: %s", src, err)
+	}
+	return g
+}
+
+// run - srcから生成したモジュールのmainを実行し、戻り値を返す
+func run(t *testing.T, src string) int {
+	t.Helper()
+	g := generate(t, src)
+
+	llvm.InitializeNativeAsmPrinter()
+	engine, err := llvm.NewExecutionEngine(g.GetModule())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Dispose()
+
+	result := engine.RunFunction(g.GetModule().NamedFunction("main"), []llvm.GenericValue{})
+	return int(int32(result.Int(true)))
+}
+
+// rejects - srcの解析かモジュールの生成がpanicするか
+func rejects(src string) (rejected bool) {
+	defer func() {
+		rejected = recover() != nil
+	}()
+
+	l := lexer.New(src)
 	p := parser.New(l)
 	tu := p.Parse()
 	g := New()
-	g.Generate(tu, input)
+	g.Generate(tu, "test", "")
+	return false
+}
+
+// expectPanic - tests[i]のsrcの解析かモジュールの生成がpanicしなければ失敗にする
+func expectPanic(t *testing.T, i int, src string) {
+	t.Helper()
+	if !rejects(src) {
+		t.Fatalf("tests[%d] - %q should be rejected", i, src)
+	}
+}
+
+// containsInstruction - 関数のいずれかの命令がmatchを満たすか
+func containsInstruction(function llvm.Value, match func(llvm.Value) bool) bool {
+	for bb := function.FirstBasicBlock(); !bb.IsNil(); bb = llvm.NextBasicBlock(bb) {
+		for inst := bb.FirstInstruction(); !inst.IsNil(); inst = llvm.NextInstruction(inst) {
+			if match(inst) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func readFile(path string) (string, error) {
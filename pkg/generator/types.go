@@ -0,0 +1,180 @@
+package generator
+
+import (
+	"../ast"
+	"fmt"
+	"llvm.org/llvm/bindings/go/llvm"
+)
+
+// typeOf - 式の型 配列は先頭要素へのポインタになる
+// 生成した値のLLVMの型と一致するので、生成し終えた式に対して使う
+func (cg *CodeGen) typeOf(expr ast.Expression) *ast.Type {
+	switch expr := expr.(type) {
+	case *ast.Number:
+		return expr.Type
+	case *ast.FloatLiteral:
+		return expr.Type
+	case *ast.StringLiteral:
+		return ast.PointerTo(ast.Char)
+	case *ast.CallExpression:
+		return cg.functions[expr.GetCallee()].ReturnType
+	case *ast.Identifier, *ast.IndexExpression, *ast.MemberExpression:
+		return decay(cg.objectType(expr))
+	case *ast.GroupedExpression:
+		return cg.typeOf(expr.Expression)
+	case *ast.PostfixExpression:
+		return cg.typeOf(expr.Left)
+	case *ast.PrefixExpression:
+		switch expr.Operator {
+		case "++", "--":
+			return cg.typeOf(expr.Right)
+		case "&":
+			return ast.PointerTo(cg.objectType(expr.Right))
+		case "*":
+			return decay(cg.objectType(expr))
+		case "!":
+			return ast.Int
+		}
+		return cg.typeOf(expr.Right).Promoted()
+	case *ast.ConditionalExpression:
+		consequence, alternative := cg.typeOf(expr.Consequence), cg.typeOf(expr.Alternative)
+		if consequence.IsArithmetic() && alternative.IsArithmetic() {
+			return ast.CommonType(consequence, alternative)
+		}
+		// 片方がヌルポインタ定数ならもう片方のポインタの型
+		if consequence.IsPointer() {
+			return consequence
+		}
+		return alternative
+	case *ast.InfixExpression:
+		switch expr.Operator {
+		case "=", "+=", "-=", "*=", "/=", "%=":
+			return cg.typeOf(expr.Left)
+		}
+		return binaryType(expr.Operator, cg.typeOf(expr.Left), cg.typeOf(expr.Right))
+	}
+	return ast.Int
+}
+
+// objectType - 変数や配列の要素など、アドレスを持つ式そのものの型 配列は配列のまま
+func (cg *CodeGen) objectType(expr ast.Expression) *ast.Type {
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		return cg.lookupVariable(expr.Name()).typ
+	case *ast.IndexExpression:
+		return cg.typeOf(expr.Left).Elem
+	case *ast.PrefixExpression:
+		if expr.Operator == "*" {
+			return cg.typeOf(expr.Right).Elem
+		}
+	case *ast.MemberExpression:
+		t, index := cg.memberOf(expr)
+		return t.Members[index].Type
+	case *ast.GroupedExpression:
+		return cg.objectType(expr.Expression)
+	}
+	return cg.typeOf(expr)
+}
+
+// memberOf - s.xやp->xのsや*pの型と、その中でのメンバの番号 構造体や共用体にないメンバならpanicする
+func (cg *CodeGen) memberOf(expr *ast.MemberExpression) (t *ast.Type, index int) {
+	if expr.IsArrow() {
+		pointer := cg.typeOf(expr.Left)
+		if !pointer.IsPointer() {
+			msg := fmt.Sprintf("%s is not a pointer", expr.Left.String())
+			panic(msg)
+		}
+		t = pointer.Elem
+	} else {
+		t = cg.objectType(expr.Left)
+	}
+
+	if !t.IsStructOrUnion() {
+		msg := fmt.Sprintf("%s is not a struct or union", expr.Left.String())
+		panic(msg)
+	}
+	if !t.IsComplete() {
+		msg := fmt.Sprintf("%s is incomplete", t.String())
+		panic(msg)
+	}
+	for i, member := range t.Members {
+		if member.Name == expr.Member.Name() {
+			return t, i
+		}
+	}
+
+	msg := fmt.Sprintf("%s has no member named %s", t.String(), expr.Member.Name())
+	panic(msg)
+}
+
+// decay - 配列は先頭要素へのポインタとして扱う
+func decay(t *ast.Type) *ast.Type {
+	if t.IsArray() {
+		return ast.PointerTo(t.Elem)
+	}
+	return t
+}
+
+// binaryType - 二項演算の結果の型
+func binaryType(operator string, lhsType, rhsType *ast.Type) *ast.Type {
+	switch operator {
+	case "&&", "||", "==", "!=", "<", ">", "<=", ">=":
+		return ast.Int
+	case "<<", ">>":
+		// シフトの結果は左辺の型 右辺は回数なので型に影響しない
+		return lhsType.Promoted()
+	}
+
+	switch {
+	case lhsType.IsPointer() && rhsType.IsPointer():
+		return ast.Long // ポインタの差
+	case lhsType.IsPointer():
+		return lhsType
+	case rhsType.IsPointer():
+		return rhsType
+	}
+	return ast.CommonType(lhsType, rhsType)
+}
+
+// convertValue - 型fromの値を型toに変換する 整数は切り詰めるか、fromの符号に従って拡張する
+// 浮動小数点数から整数へは0の方向に切り捨てる 変換できなければokはfalse
+func (cg *CodeGen) convertValue(value llvm.Value, from, to *ast.Type) (converted llvm.Value, ok bool) {
+	t := cg.llvmType(to)
+	if value.Type() == t {
+		return value, true
+	}
+
+	switch {
+	case from.IsInteger() && to.IsInteger():
+		switch {
+		case from.Size() > to.Size():
+			return cg.builder.CreateTrunc(value, t, "trunc_tmp"), true
+		case from.Unsigned:
+			return cg.builder.CreateZExt(value, t, "zext_tmp"), true
+		default:
+			return cg.builder.CreateSExt(value, t, "sext_tmp"), true
+		}
+	case from.IsInteger() && to.IsFloating():
+		if from.Unsigned {
+			return cg.builder.CreateUIToFP(value, t, "uitofp_tmp"), true
+		}
+		return cg.builder.CreateSIToFP(value, t, "sitofp_tmp"), true
+	case from.IsFloating() && to.IsInteger():
+		if to.Unsigned {
+			return cg.builder.CreateFPToUI(value, t, "fptoui_tmp"), true
+		}
+		return cg.builder.CreateFPToSI(value, t, "fptosi_tmp"), true
+	case from.IsFloating() && to.IsFloating():
+		if from.Size() > to.Size() {
+			return cg.builder.CreateFPTrunc(value, t, "fptrunc_tmp"), true
+		}
+		return cg.builder.CreateFPExt(value, t, "fpext_tmp"), true
+	}
+	return value, false
+}
+
+// convert - 算術型の値を型fromから型toに変換する
+func (cg *CodeGen) convert(value llvm.Value, from, to *ast.Type) llvm.Value {
+	converted, _ := cg.convertValue(value, from, to)
+	return converted
+}
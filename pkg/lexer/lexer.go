@@ -3,6 +3,9 @@ package lexer
 import (
 	"../token"
 	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 )
 
 type Lexer struct {
@@ -94,15 +97,101 @@ func New(source string) *Lexer {
 
 		switch char {
 		case '=':
-			lexer.PushToken(token.New(token.ASSIGN, string(char), line))
+			if peekChar(source, i) == '=' {
+				lexer.PushToken(token.New(token.EQ, "==", line))
+				skip++
+			} else {
+				lexer.PushToken(token.New(token.ASSIGN, string(char), line))
+			}
+		case '!':
+			if peekChar(source, i) == '=' {
+				lexer.PushToken(token.New(token.NOT_EQ, "!=", line))
+				skip++
+			} else {
+				lexer.PushToken(token.New(token.BANG, string(char), line))
+			}
+		case '&':
+			if peekChar(source, i) == '&' {
+				lexer.PushToken(token.New(token.AND, "&&", line))
+				skip++
+			} else {
+				lexer.PushToken(token.New(token.AMPERSAND, string(char), line))
+			}
+		case '|':
+			if peekChar(source, i) == '|' {
+				lexer.PushToken(token.New(token.OR, "||", line))
+				skip++
+			} else {
+				lexer.PushToken(token.New(token.PIPE, string(char), line))
+			}
+		case '^':
+			lexer.PushToken(token.New(token.CARET, string(char), line))
+		case '<':
+			if peekChar(source, i) == '=' {
+				lexer.PushToken(token.New(token.LE, "<=", line))
+				skip++
+			} else if peekChar(source, i) == '<' {
+				lexer.PushToken(token.New(token.LSHIFT, "<<", line))
+				skip++
+			} else {
+				lexer.PushToken(token.New(token.LT, string(char), line))
+			}
+		case '>':
+			if peekChar(source, i) == '=' {
+				lexer.PushToken(token.New(token.GE, ">=", line))
+				skip++
+			} else if peekChar(source, i) == '>' {
+				lexer.PushToken(token.New(token.RSHIFT, ">>", line))
+				skip++
+			} else {
+				lexer.PushToken(token.New(token.GT, string(char), line))
+			}
 		case '+':
-			lexer.PushToken(token.New(token.PLUS, string(char), line))
+			if peekChar(source, i) == '+' {
+				lexer.PushToken(token.New(token.INCREMENT, "++", line))
+				skip++
+			} else if peekChar(source, i) == '=' {
+				lexer.PushToken(token.New(token.PLUS_ASSIGN, "+=", line))
+				skip++
+			} else {
+				lexer.PushToken(token.New(token.PLUS, string(char), line))
+			}
 		case '-':
-			lexer.PushToken(token.New(token.MINUS, string(char), line))
+			if peekChar(source, i) == '-' {
+				lexer.PushToken(token.New(token.DECREMENT, "--", line))
+				skip++
+			} else if peekChar(source, i) == '=' {
+				lexer.PushToken(token.New(token.MINUS_ASSIGN, "-=", line))
+				skip++
+			} else if peekChar(source, i) == '>' {
+				lexer.PushToken(token.New(token.ARROW, "->", line))
+				skip++
+			} else {
+				lexer.PushToken(token.New(token.MINUS, string(char), line))
+			}
 		case '*':
-			lexer.PushToken(token.New(token.ASTERISK, string(char), line))
+			if peekChar(source, i) == '=' {
+				lexer.PushToken(token.New(token.ASTERISK_ASSIGN, "*=", line))
+				skip++
+			} else {
+				lexer.PushToken(token.New(token.ASTERISK, string(char), line))
+			}
 		case '/':
-			lexer.PushToken(token.New(token.SLASH, string(char), line))
+			if peekChar(source, i) == '=' {
+				lexer.PushToken(token.New(token.SLASH_ASSIGN, "/=", line))
+				skip++
+			} else {
+				lexer.PushToken(token.New(token.SLASH, string(char), line))
+			}
+		case '%':
+			if peekChar(source, i) == '=' {
+				lexer.PushToken(token.New(token.PERCENT_ASSIGN, "%=", line))
+				skip++
+			} else {
+				lexer.PushToken(token.New(token.PERCENT, string(char), line))
+			}
+		case '~':
+			lexer.PushToken(token.New(token.TILDE, string(char), line))
 		case ',':
 			lexer.PushToken(token.New(token.COMMA, string(char), line))
 		case ';':
@@ -121,6 +210,20 @@ func New(source string) *Lexer {
 			lexer.PushToken(token.New(token.RBRACKET, string(char), line))
 		case ':':
 			lexer.PushToken(token.New(token.COLON, string(char), line))
+		case '?':
+			lexer.PushToken(token.New(token.QUESTION, string(char), line))
+		case '\'':
+			// 文字定数の値はそのcharの値
+			value, length := readCharacter(source, i, line)
+			tok := token.New(token.CHARACTER, source[i:i+length], line)
+			tok.Number = int(int8(value))
+			lexer.PushToken(tok)
+			skip += utf8.RuneCountInString(source[i:i+length]) - 1
+		case '"':
+			// 文字列のLiteralはエスケープを解釈した後の中身
+			text, length := readString(source, i, line)
+			lexer.PushToken(token.New(token.STRING, text, line))
+			skip += utf8.RuneCountInString(source[i:i+length]) - 1
 		case '\n':
 			line++
 		default:
@@ -140,24 +243,64 @@ func New(source string) *Lexer {
 				switch identifier {
 				case "int":
 					lexer.PushToken(token.New(token.INTTYPE, identifier, line))
+				case "char":
+					lexer.PushToken(token.New(token.CHARTYPE, identifier, line))
+				case "float":
+					lexer.PushToken(token.New(token.FLOATTYPE, identifier, line))
+				case "double":
+					lexer.PushToken(token.New(token.DOUBLETYPE, identifier, line))
+				case "struct":
+					lexer.PushToken(token.New(token.STRUCT, identifier, line))
+				case "union":
+					lexer.PushToken(token.New(token.UNION, identifier, line))
+				case "short":
+					lexer.PushToken(token.New(token.SHORTTYPE, identifier, line))
+				case "long":
+					lexer.PushToken(token.New(token.LONGTYPE, identifier, line))
+				case "signed":
+					lexer.PushToken(token.New(token.SIGNED, identifier, line))
+				case "unsigned":
+					lexer.PushToken(token.New(token.UNSIGNED, identifier, line))
+				case "void":
+					lexer.PushToken(token.New(token.VOIDTYPE, identifier, line))
 				case "return":
 					lexer.PushToken(token.New(token.RETURN, identifier, line))
+				case "if":
+					lexer.PushToken(token.New(token.IF, identifier, line))
+				case "else":
+					lexer.PushToken(token.New(token.ELSE, identifier, line))
+				case "while":
+					lexer.PushToken(token.New(token.WHILE, identifier, line))
+				case "for":
+					lexer.PushToken(token.New(token.FOR, identifier, line))
+				case "do":
+					lexer.PushToken(token.New(token.DO, identifier, line))
+				case "switch":
+					lexer.PushToken(token.New(token.SWITCH, identifier, line))
+				case "case":
+					lexer.PushToken(token.New(token.CASE, identifier, line))
+				case "default":
+					lexer.PushToken(token.New(token.DEFAULT, identifier, line))
+				case "break":
+					lexer.PushToken(token.New(token.BREAK, identifier, line))
+				case "continue":
+					lexer.PushToken(token.New(token.CONTINUE, identifier, line))
+				case "goto":
+					lexer.PushToken(token.New(token.GOTO, identifier, line))
 				default:
 					lexer.PushToken(token.New(token.IDENT, identifier, line))
 				}
 
-			case isDigit(char):
-				number := string(char)
-				for j := 1; ; j++ {
-					char := rune(source[i+j])
-					if isDigit(char) {
-						number += string(char)
-						skip++
-					} else {
-						break
-					}
+			case isDigit(char), char == '.' && isDigit(rune(peekChar(source, i))):
+				number, floating := readNumber(source, i, line)
+				skip += len(number) - 1
+				if floating {
+					lexer.PushToken(token.New(token.FLOATING, number, line))
+				} else {
+					lexer.PushToken(token.New(token.DIGIT, number, line))
 				}
-				lexer.PushToken(token.New(token.DIGIT, number, line))
+			case char == '.':
+				lexer.PushToken(token.New(token.DOT, string(char), line))
 			default:
 				lexer.PushToken(token.New(token.ILLEGAL, string(char), line))
 			}
@@ -168,6 +311,160 @@ func New(source string) *Lexer {
 	return lexer
 }
 
+// readNumber - i番目から始まる数値定数を読む 小数点か指数があれば浮動小数点数
+// 型を表す接尾辞(整数はu, l, ll 浮動小数点数はf)も含める 整数の接尾辞の組み合わせは構文解析で調べる
+func readNumber(source string, i, line int) (literal string, floating bool) {
+	j := skipDigits(source, i)
+	if j < len(source) && source[j] == '.' {
+		floating = true
+		j = skipDigits(source, j+1)
+	}
+	if j < len(source) && (source[j] == 'e' || source[j] == 'E') {
+		floating = true
+		j++
+		if j < len(source) && (source[j] == '+' || source[j] == '-') {
+			j++
+		}
+		if j >= len(source) || !isDigit(rune(source[j])) {
+			panic(fmt.Sprintf("line %d: exponent has no digits", line))
+		}
+		j = skipDigits(source, j)
+	}
+
+	suffixes := "uUlL"
+	if floating {
+		suffixes = "fF"
+	}
+	for j < len(source) && strings.ContainsRune(suffixes, rune(source[j])) {
+		j++
+	}
+	return source[i:j], floating
+}
+
+func skipDigits(source string, i int) int {
+	for i < len(source) && isDigit(rune(source[i])) {
+		i++
+	}
+	return i
+}
+
+// readCharacter - i番目の'から始まる文字定数を読み、値と'を含めた長さを返す
+func readCharacter(source string, i, line int) (value byte, length int) {
+	j := i + 1
+	if j >= len(source) || source[j] == '\'' || source[j] == '\n' {
+		panic(fmt.Sprintf("line %d: empty character constant", line))
+	}
+
+	if source[j] == '\\' {
+		var n int
+		value, n = readEscape(source, j, line)
+		j += n
+	} else {
+		value = source[j]
+		j++
+	}
+
+	if j >= len(source) || source[j] != '\'' {
+		panic(fmt.Sprintf("line %d: unterminated character constant", line))
+	}
+	return value, j + 1 - i
+}
+
+// readString - i番目の"から始まる文字列を読み、中身と"を含めた長さを返す
+func readString(source string, i, line int) (text string, length int) {
+	var out bytes.Buffer
+
+	j := i + 1
+	for {
+		if j >= len(source) || source[j] == '\n' {
+			panic(fmt.Sprintf("line %d: unterminated string literal", line))
+		}
+		if source[j] == '"' {
+			break
+		}
+
+		if source[j] == '\\' {
+			value, n := readEscape(source, j, line)
+			out.WriteByte(value)
+			j += n
+		} else {
+			out.WriteByte(source[j])
+			j++
+		}
+	}
+	return out.String(), j + 1 - i
+}
+
+// readEscape - i番目の\から始まるエスケープシーケンスを読み、値と長さを返す
+func readEscape(source string, i, line int) (value byte, length int) {
+	if i+1 >= len(source) {
+		panic(fmt.Sprintf("line %d: unterminated escape sequence", line))
+	}
+
+	switch c := source[i+1]; c {
+	case 'n':
+		return '\n', 2
+	case 't':
+		return '\t', 2
+	case 'r':
+		return '\r', 2
+	case 'a':
+		return '\a', 2
+	case 'b':
+		return '\b', 2
+	case 'f':
+		return '\f', 2
+	case 'v':
+		return '\v', 2
+	case '\\', '\'', '"', '?':
+		return c, 2
+	case 'x':
+		// \xhh 16進数は続く限り読む
+		n := 0
+		for j := i + 2; j < len(source) && isHexDigit(rune(source[j])); j++ {
+			value = value<<4 | hexValue(source[j])
+			n++
+		}
+		if n == 0 {
+			panic(fmt.Sprintf("line %d: \\x used with no following hex digits", line))
+		}
+		return value, n + 2
+	default:
+		// \ooo 8進数は3桁まで
+		n := 0
+		for j := i + 1; j < len(source) && n < 3 && '0' <= source[j] && source[j] <= '7'; j++ {
+			value = value<<3 | (source[j] - '0')
+			n++
+		}
+		if n == 0 {
+			panic(fmt.Sprintf("line %d: unknown escape sequence \\%c", line, c))
+		}
+		return value, n + 1
+	}
+}
+
+func isHexDigit(char rune) bool {
+	return isDigit(char) || 'a' <= char && char <= 'f' || 'A' <= char && char <= 'F'
+}
+
+func hexValue(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	}
+	return c - 'A' + 10
+}
+
+// peekChar - i番目の次の文字を返す 末尾に達している場合は0
+func peekChar(source string, i int) byte {
+	if i+1 < len(source) {
+		return source[i+1]
+	}
+	return 0
+}
+
 func isWhitespace(char rune) bool {
 	return char == ' ' || char == '\t' || char == '\r'
 }
@@ -3,13 +3,14 @@ package lexer
 import (
 	"../token"
 	"io/ioutil"
+	"math"
 	"testing"
 )
 
 func TestNew(t *testing.T) {
 	input, err := readFile("../../test/test.dc")
 	if err != nil {
-		t.Fatalf(err.Error())
+		t.Fatal(err)
 	}
 
 	tests := []struct {
@@ -48,10 +49,13 @@ func TestNew(t *testing.T) {
 		{token.ASSIGN, "="},
 		{token.DIGIT, "10"},
 		{token.SEMICOLON, ";"},
+		{token.IDENT, "printnum"},
+		{token.LPAREN, "("},
 		{token.IDENT, "test"},
 		{token.LPAREN, "("},
 		{token.IDENT, "i"},
 		{token.RPAREN, ")"},
+		{token.RPAREN, ")"},
 		{token.SEMICOLON, ";"},
 		{token.RETURN, "return"},
 		{token.DIGIT, "0"},
@@ -78,6 +82,405 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestComparisonOperators(t *testing.T) {
+	input := `if (a < b) a == b; else a != b <= c >= d > e = f;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IF, "if"},
+		{token.LPAREN, "("},
+		{token.IDENT, "a"},
+		{token.LT, "<"},
+		{token.IDENT, "b"},
+		{token.RPAREN, ")"},
+		{token.IDENT, "a"},
+		{token.EQ, "=="},
+		{token.IDENT, "b"},
+		{token.SEMICOLON, ";"},
+		{token.ELSE, "else"},
+		{token.IDENT, "a"},
+		{token.NOT_EQ, "!="},
+		{token.IDENT, "b"},
+		{token.LE, "<="},
+		{token.IDENT, "c"},
+		{token.GE, ">="},
+		{token.IDENT, "d"},
+		{token.GT, ">"},
+		{token.IDENT, "e"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "f"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.GetToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		l.GetNextToken()
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	input := `!a && b || !(c != d)`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.BANG, "!"},
+		{token.IDENT, "a"},
+		{token.AND, "&&"},
+		{token.IDENT, "b"},
+		{token.OR, "||"},
+		{token.BANG, "!"},
+		{token.LPAREN, "("},
+		{token.IDENT, "c"},
+		{token.NOT_EQ, "!="},
+		{token.IDENT, "d"},
+		{token.RPAREN, ")"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.GetToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		l.GetNextToken()
+	}
+}
+
+func TestMemberOperators(t *testing.T) {
+	input := `struct point p; union value v; q->next.x -= .5 - -1;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.STRUCT, "struct"},
+		{token.IDENT, "point"},
+		{token.IDENT, "p"},
+		{token.SEMICOLON, ";"},
+		{token.UNION, "union"},
+		{token.IDENT, "value"},
+		{token.IDENT, "v"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "q"},
+		{token.ARROW, "->"},
+		{token.IDENT, "next"},
+		{token.DOT, "."},
+		{token.IDENT, "x"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.FLOATING, ".5"},
+		{token.MINUS, "-"},
+		{token.MINUS, "-"},
+		{token.DIGIT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.GetToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		l.GetNextToken()
+	}
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	input := `a % b & c | d ^ e << f >> g <= h && i || j;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.PERCENT, "%"},
+		{token.IDENT, "b"},
+		{token.AMPERSAND, "&"},
+		{token.IDENT, "c"},
+		{token.PIPE, "|"},
+		{token.IDENT, "d"},
+		{token.CARET, "^"},
+		{token.IDENT, "e"},
+		{token.LSHIFT, "<<"},
+		{token.IDENT, "f"},
+		{token.RSHIFT, ">>"},
+		{token.IDENT, "g"},
+		{token.LE, "<="},
+		{token.IDENT, "h"},
+		{token.AND, "&&"},
+		{token.IDENT, "i"},
+		{token.OR, "||"},
+		{token.IDENT, "j"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.GetToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		l.GetNextToken()
+	}
+}
+
+func TestAssignmentOperators(t *testing.T) {
+	input := `a++ + --b; a += 1; a -= 2; a *= 3; a /= 4; a %= 5;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.INCREMENT, "++"},
+		{token.PLUS, "+"},
+		{token.DECREMENT, "--"},
+		{token.IDENT, "b"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.DIGIT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.DIGIT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.DIGIT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.SLASH_ASSIGN, "/="},
+		{token.DIGIT, "4"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.PERCENT_ASSIGN, "%="},
+		{token.DIGIT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.GetToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		l.GetNextToken()
+	}
+}
+
+func TestCharLiterals(t *testing.T) {
+	input := `char c = 'a'; '\n' '\\' '\'' '\0' '\101' '\x7f' '\377'`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedNumber  int
+	}{
+		{token.CHARTYPE, "char", 0},
+		{token.IDENT, "c", 0},
+		{token.ASSIGN, "=", 0},
+		{token.CHARACTER, "'a'", 97},
+		{token.SEMICOLON, ";", 0},
+		{token.CHARACTER, "'\\n'", 10},
+		{token.CHARACTER, "'\\\\'", 92},
+		{token.CHARACTER, "'\\''", 39},
+		{token.CHARACTER, "'\\0'", 0},
+		{token.CHARACTER, "'\\101'", 65},
+		{token.CHARACTER, "'\\x7f'", 127},
+		{token.CHARACTER, "'\\377'", -1},
+		{token.EOF, "", 0},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.GetToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Type == token.CHARACTER && tok.Number != tt.expectedNumber {
+			t.Fatalf("tests[%d] - number wrong. expected=%d, got=%d", i, tt.expectedNumber, tok.Number)
+		}
+		l.GetNextToken()
+	}
+}
+
+func TestStringLiterals(t *testing.T) {
+	input := `"hello" "a\tb\n" "\"q\"" "" "\x41\102"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.STRING, "hello"},
+		{token.STRING, "a\tb\n"},
+		{token.STRING, "\"q\""},
+		{token.STRING, ""},
+		{token.STRING, "AB"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.GetToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		l.GetNextToken()
+	}
+}
+
+func TestIntegerTypes(t *testing.T) {
+	input := `unsigned long long x = 10ULL; signed short s = 7l; 5u 3;
+	9223372036854775807 9223372036854775808u 18446744073709551615ULL;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedNumber  int
+	}{
+		{token.UNSIGNED, "unsigned", 0},
+		{token.LONGTYPE, "long", 0},
+		{token.LONGTYPE, "long", 0},
+		{token.IDENT, "x", 0},
+		{token.ASSIGN, "=", 0},
+		{token.DIGIT, "10ULL", 10},
+		{token.SEMICOLON, ";", 0},
+		{token.SIGNED, "signed", 0},
+		{token.SHORTTYPE, "short", 0},
+		{token.IDENT, "s", 0},
+		{token.ASSIGN, "=", 0},
+		{token.DIGIT, "7l", 7},
+		{token.SEMICOLON, ";", 0},
+		{token.DIGIT, "5u", 5},
+		{token.DIGIT, "3", 3},
+		{token.SEMICOLON, ";", 0},
+		{token.DIGIT, "9223372036854775807", math.MaxInt64},
+		{token.DIGIT, "9223372036854775808u", math.MinInt64},
+		{token.DIGIT, "18446744073709551615ULL", -1},
+		{token.SEMICOLON, ";", 0},
+		{token.EOF, "", 0},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.GetToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Type == token.DIGIT && tok.Number != tt.expectedNumber {
+			t.Fatalf("tests[%d] - number wrong. expected=%d, got=%d", i, tt.expectedNumber, tok.Number)
+		}
+		l.GetNextToken()
+	}
+}
+
+func TestFloatLiterals(t *testing.T) {
+	input := `double d = 1.5; float f = .25f; 2e10 1.E-3 3.0F 7;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedFloat   float64
+	}{
+		{token.DOUBLETYPE, "double", 0},
+		{token.IDENT, "d", 0},
+		{token.ASSIGN, "=", 0},
+		{token.FLOATING, "1.5", 1.5},
+		{token.SEMICOLON, ";", 0},
+		{token.FLOATTYPE, "float", 0},
+		{token.IDENT, "f", 0},
+		{token.ASSIGN, "=", 0},
+		{token.FLOATING, ".25f", 0.25},
+		{token.SEMICOLON, ";", 0},
+		{token.FLOATING, "2e10", 2e10},
+		{token.FLOATING, "1.E-3", 1e-3},
+		{token.FLOATING, "3.0F", 3},
+		{token.DIGIT, "7", 0},
+		{token.SEMICOLON, ";", 0},
+		{token.EOF, "", 0},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.GetToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Type == token.FLOATING && tok.Float != tt.expectedFloat {
+			t.Fatalf("tests[%d] - float wrong. expected=%g, got=%g", i, tt.expectedFloat, tok.Float)
+		}
+		l.GetNextToken()
+	}
+}
+
+func TestInvalidLiterals(t *testing.T) {
+	tests := []string{
+		`'a`,
+		`''`,
+		`'ab'`,
+		`'\q'`,
+		`"abc`,
+		"\"ab\ncd\"",
+		`'\x'`,
+		`1e;`,
+		`2.5e+;`,
+		`18446744073709551616`,
+	}
+
+	for i, tt := range tests {
+		expectPanic(t, i, tt)
+	}
+}
+
 func readFile(path string) (string, error) {
 	b, err := ioutil.ReadFile(path)
 	if err != nil {
@@ -86,3 +489,15 @@ func readFile(path string) (string, error) {
 	lines := string(b)
 	return lines, nil
 }
+
+// expectPanic - tests[i]のsrcの字句解析がpanicしなければ失敗にする
+func expectPanic(t *testing.T, i int, src string) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("tests[%d] - %q should be rejected", i, src)
+		}
+	}()
+
+	New(src)
+}
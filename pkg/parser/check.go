@@ -1,5 +1,10 @@
 package parser
 
+import (
+	"../ast"
+	"fmt"
+)
+
 func (p *Parser) checkReDefinition(fn Function) (ok bool) {
 	for _, prototype := range p.prototypeTable {
 		if fn.Name == prototype.Name {
@@ -10,8 +15,8 @@ func (p *Parser) checkReDefinition(fn Function) (ok bool) {
 
 	for _, function := range p.functionTable {
 		if fn.Name == function.Name {
-			if fn.Argc != function.Argc {
-				// 関数が既に定義されているが引数の数が合わないとき
+			if fn.Argc != function.Argc || fn.Void != function.Void {
+				// 関数が既に定義されているが引数の数か戻り値の型が合わないとき
 				return false
 			}
 		}
@@ -21,11 +26,11 @@ func (p *Parser) checkReDefinition(fn Function) (ok bool) {
 }
 
 func (p *Parser) checkCorrectDefinition(fn Function) (ok bool) {
-	// 関数がプロトタイプ宣言されていた場合に、プロトタイプ宣言と関数定義の引数の数が同一であることを確認する
+	// 関数がプロトタイプ宣言されていた場合に、プロトタイプ宣言と関数定義の引数の数と戻り値の型が同一であることを確認する
 	correctDeclared := true
 	for _, prototype := range p.prototypeTable {
 		if fn.Name == prototype.Name {
-			if fn.Argc == prototype.Argc {
+			if fn.Argc == prototype.Argc && fn.Void == prototype.Void {
 				correctDeclared = true
 				break
 			} else {
@@ -51,3 +56,443 @@ func (p *Parser) checkCorrectDefinition(fn Function) (ok bool) {
 
 	return true
 }
+
+// checkConflictingTypes - 宣言済みや定義済みの同名の関数と、戻り値と仮引数の型が全て同じか確認する
+// 仮引数の名前は違ってもよい
+func (p *Parser) checkConflictingTypes(fn Function) (ok bool) {
+	for _, table := range [][]Function{p.prototypeTable, p.functionTable} {
+		for _, other := range table {
+			if fn.Name == other.Name && !sameSignature(fn.Prototype, other.Prototype) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func sameSignature(a, b *ast.Prototype) bool {
+	if !a.ReturnType.SameAs(b.ReturnType) || len(a.ParameterTypes) != len(b.ParameterTypes) {
+		return false
+	}
+	for i := range a.ParameterTypes {
+		if !a.ParameterTypes[i].SameAs(b.ParameterTypes[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Parser) checkLabels() (ok bool, label string) {
+	// gotoで使われた全てのラベルが関数内で定義されていることを確認する
+	for _, label := range p.gotoTable {
+		if !contains(p.labelTable, label) {
+			return false, label
+		}
+	}
+
+	return true, ""
+}
+
+// completesAll - 文の並びを実行し終えて後ろへ抜けうるか
+// ラベルを含む文にはgotoで飛んでこられるので、直前で抜けていても到達しうるとみなす
+func completesAll(stmts []ast.Statement) bool {
+	reachable := true
+	for _, stmt := range stmts {
+		if containsLabel(stmt) {
+			reachable = true
+		}
+		if reachable {
+			reachable = completes(stmt)
+		}
+	}
+	return reachable
+}
+
+// completes - 文を実行し終えて次の文へ進みうるか
+func completes(stmt ast.Statement) bool {
+	switch stmt := stmt.(type) {
+	case *ast.ReturnStatement, *ast.GotoStatement, *ast.BreakStatement, *ast.ContinueStatement:
+		return false
+	case *ast.BlockStatement:
+		return completesAll(stmt.Statements)
+	case *ast.LabeledStatement:
+		return completes(stmt.Statement)
+	case *ast.IfStatement:
+		if stmt.Alternative == nil {
+			return true
+		}
+		return completes(stmt.Consequence) || completes(stmt.Alternative)
+	case *ast.WhileStatement:
+		return !isAlwaysTrue(stmt.Condition) || hasBreak(stmt.Body)
+	case *ast.ForStatement:
+		// 条件を省略したforは無限ループ
+		infinite := stmt.Condition == nil || isAlwaysTrue(stmt.Condition)
+		return !infinite || hasBreak(stmt.Body)
+	case *ast.DoWhileStatement:
+		if hasBreak(stmt.Body) {
+			return true
+		}
+		return !isAlwaysTrue(stmt.Condition) && (completes(stmt.Body) || hasContinue(stmt.Body))
+	case *ast.SwitchStatement:
+		// defaultがなければどの節も実行せずに抜けうる
+		hasDefault := false
+		for _, clause := range stmt.Cases {
+			if clause.IsDefault() {
+				hasDefault = true
+			}
+			for _, s := range clause.Statements {
+				if hasBreak(s) {
+					return true
+				}
+			}
+		}
+		if !hasDefault || len(stmt.Cases) == 0 {
+			return true
+		}
+		// 前の節からはフォールスルーで最後の節に入る
+		return completesAll(stmt.Cases[len(stmt.Cases)-1].Statements)
+	}
+	return true
+}
+
+// hasBreak - 文がそれを囲むループかswitchを抜けるbreakを含むか
+func hasBreak(stmt ast.Statement) bool {
+	switch stmt := stmt.(type) {
+	case *ast.BreakStatement:
+		return true
+	case *ast.BlockStatement:
+		for _, s := range stmt.Statements {
+			if hasBreak(s) {
+				return true
+			}
+		}
+	case *ast.LabeledStatement:
+		return hasBreak(stmt.Statement)
+	case *ast.IfStatement:
+		return hasBreak(stmt.Consequence) || (stmt.Alternative != nil && hasBreak(stmt.Alternative))
+	}
+	// 内側のループとswitchのbreakはそれ自身を抜ける
+	return false
+}
+
+// hasContinue - 文がそれを囲むループのcontinueを含むか
+func hasContinue(stmt ast.Statement) bool {
+	switch stmt := stmt.(type) {
+	case *ast.ContinueStatement:
+		return true
+	case *ast.BlockStatement:
+		for _, s := range stmt.Statements {
+			if hasContinue(s) {
+				return true
+			}
+		}
+	case *ast.LabeledStatement:
+		return hasContinue(stmt.Statement)
+	case *ast.IfStatement:
+		return hasContinue(stmt.Consequence) || (stmt.Alternative != nil && hasContinue(stmt.Alternative))
+	case *ast.SwitchStatement:
+		// switch内のcontinueは外側のループのもの
+		for _, clause := range stmt.Cases {
+			for _, s := range clause.Statements {
+				if hasContinue(s) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// containsLabel - 文が内側のブロックや本体も含めてラベルの付いた文を持つか
+func containsLabel(stmt ast.Statement) bool {
+	switch stmt := stmt.(type) {
+	case *ast.LabeledStatement:
+		return true
+	case *ast.BlockStatement:
+		for _, s := range stmt.Statements {
+			if containsLabel(s) {
+				return true
+			}
+		}
+	case *ast.IfStatement:
+		return containsLabel(stmt.Consequence) || (stmt.Alternative != nil && containsLabel(stmt.Alternative))
+	case *ast.WhileStatement:
+		return containsLabel(stmt.Body)
+	case *ast.ForStatement:
+		return containsLabel(stmt.Body)
+	case *ast.DoWhileStatement:
+		return containsLabel(stmt.Body)
+	case *ast.SwitchStatement:
+		for _, clause := range stmt.Cases {
+			for _, s := range clause.Statements {
+				if containsLabel(s) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isAlwaysTrue - 条件がコンパイル時に真と決まるか
+func isAlwaysTrue(cond ast.Expression) bool {
+	value, ok := evalConstant(cond)
+	return ok && value != 0
+}
+
+// checkLvalue - 代入先になれる式(変数)でなければpanicする
+func (p *Parser) checkLvalue(expr ast.Expression) {
+	if !isLvalue(expr) {
+		msg := fmt.Sprintf("%s is not assignable", expr.String())
+		panic(msg)
+	}
+}
+
+func isLvalue(expr ast.Expression) bool {
+	switch expr := expr.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+		return true
+	case *ast.GroupedExpression:
+		return isLvalue(expr.Expression)
+	case *ast.PrefixExpression:
+		return expr.Operator == "*"
+	case *ast.MemberExpression:
+		// p->xは常に、s.xはsが代入先になれるとき
+		return expr.IsArrow() || isLvalue(expr.Left)
+	}
+
+	return false
+}
+
+// evalConstant - 整数定数式をコンパイル時に評価する 整数の定数式でなければokはfalse
+func evalConstant(expr ast.Expression) (value int, ok bool) {
+	c, ok := evalTypedConstant(expr)
+	if !ok || !c.t.IsInteger() {
+		return 0, false
+	}
+	return c.intValue, true
+}
+
+// constant - 定数式の値と型 浮動小数点型ならfloatValue、整数型ならintValueを使う
+type constant struct {
+	t          *ast.Type
+	intValue   int
+	floatValue float64
+}
+
+func integerConstant(value int, t *ast.Type) constant {
+	return constant{t: t, intValue: t.Normalize(value)}
+}
+
+func floatConstant(value float64, t *ast.Type) constant {
+	if t.Kind == ast.FloatType {
+		value = float64(float32(value))
+	}
+	return constant{t: t, floatValue: value}
+}
+
+func (c constant) isZero() bool {
+	if c.t.IsFloating() {
+		return c.floatValue == 0
+	}
+	return c.intValue == 0
+}
+
+// convertTo - 型tに変換した値 浮動小数点数から整数へは0の方向に切り捨てる
+func (c constant) convertTo(t *ast.Type) constant {
+	switch {
+	case c.t.IsFloating() && t.IsFloating():
+		return floatConstant(c.floatValue, t)
+	case c.t.IsFloating() && t.Unsigned:
+		return integerConstant(int(uint64(c.floatValue)), t)
+	case c.t.IsFloating():
+		return integerConstant(int(c.floatValue), t)
+	case t.IsFloating() && c.t.Unsigned:
+		return floatConstant(float64(uint64(c.intValue)), t)
+	case t.IsFloating():
+		return floatConstant(float64(c.intValue), t)
+	}
+	return integerConstant(c.intValue, t)
+}
+
+// evalTypedConstant - 定数式の値と型 演算は実行時と同じく整数拡張と通常の算術型変換をした型で行う
+func evalTypedConstant(expr ast.Expression) (c constant, ok bool) {
+	switch expr := expr.(type) {
+	case *ast.Number:
+		return integerConstant(expr.Val(), expr.Type), true
+	case *ast.FloatLiteral:
+		return floatConstant(expr.Value, expr.Type), true
+	case *ast.GroupedExpression:
+		return evalTypedConstant(expr.Expression)
+	case *ast.PrefixExpression:
+		right, ok := evalTypedConstant(expr.Right)
+		if !ok {
+			return constant{}, false
+		}
+
+		t := right.t.Promoted()
+		right = right.convertTo(t)
+		switch {
+		case expr.Operator == "!":
+			return integerConstant(boolToInt(right.isZero()), ast.Int), true
+		case expr.Operator == "-" && t.IsFloating():
+			return floatConstant(-right.floatValue, t), true
+		case expr.Operator == "-":
+			return integerConstant(-right.intValue, t), true
+		case expr.Operator == "+":
+			return right, true
+		case expr.Operator == "~" && t.IsInteger():
+			return integerConstant(^right.intValue, t), true
+		}
+	case *ast.ConditionalExpression:
+		cond, ok := evalTypedConstant(expr.Condition)
+		if !ok {
+			return constant{}, false
+		}
+
+		// 選ばれない方は評価できなくてもよい 型は両方が分かれば揃える
+		chosen, other := expr.Consequence, expr.Alternative
+		if cond.isZero() {
+			chosen, other = other, chosen
+		}
+		value, ok := evalTypedConstant(chosen)
+		if !ok {
+			return constant{}, false
+		}
+		if otherValue, ok := evalTypedConstant(other); ok {
+			return value.convertTo(ast.CommonType(value.t, otherValue.t)), true
+		}
+		return value, true
+	case *ast.InfixExpression:
+		left, ok := evalTypedConstant(expr.Left)
+		if !ok {
+			return constant{}, false
+		}
+		right, ok := evalTypedConstant(expr.Right)
+		if !ok {
+			return constant{}, false
+		}
+
+		switch expr.Operator {
+		case "&&":
+			return integerConstant(boolToInt(!left.isZero() && !right.isZero()), ast.Int), true
+		case "||":
+			return integerConstant(boolToInt(!left.isZero() || !right.isZero()), ast.Int), true
+		case "<<", ">>":
+			// シフトの型は左辺だけで決まる
+			if !left.t.IsInteger() || !right.t.IsInteger() {
+				return constant{}, false
+			}
+			t := left.t.Promoted()
+			return evalShift(expr.Operator, left.convertTo(t).intValue, right.intValue, t)
+		}
+
+		t := ast.CommonType(left.t, right.t)
+		left, right = left.convertTo(t), right.convertTo(t)
+		if t.IsFloating() {
+			return evalFloatOperation(expr.Operator, left.floatValue, right.floatValue, t)
+		}
+		return evalIntegerOperation(expr.Operator, left.intValue, right.intValue, t)
+	}
+
+	return constant{}, false
+}
+
+func evalShift(operator string, left, right int, t *ast.Type) (constant, bool) {
+	if right < 0 || right >= t.Size()*8 {
+		return constant{}, false
+	}
+	if operator == "<<" {
+		return integerConstant(left<<uint(right), t), true
+	}
+	if t.Unsigned {
+		return integerConstant(int(uint64(left)>>uint(right)), t), true
+	}
+	return integerConstant(left>>uint(right), t), true
+}
+
+// evalIntegerOperation - 符号なしの比較と除算はuint64で行う
+func evalIntegerOperation(operator string, left, right int, t *ast.Type) (constant, bool) {
+	switch operator {
+	case "+":
+		return integerConstant(left+right, t), true
+	case "-":
+		return integerConstant(left-right, t), true
+	case "*":
+		return integerConstant(left*right, t), true
+	case "/", "%":
+		if right == 0 {
+			return constant{}, false
+		}
+		if t.Unsigned && operator == "/" {
+			return integerConstant(int(uint64(left)/uint64(right)), t), true
+		}
+		if t.Unsigned {
+			return integerConstant(int(uint64(left)%uint64(right)), t), true
+		}
+		if operator == "/" {
+			return integerConstant(left/right, t), true
+		}
+		return integerConstant(left%right, t), true
+	case "&":
+		return integerConstant(left&right, t), true
+	case "|":
+		return integerConstant(left|right, t), true
+	case "^":
+		return integerConstant(left^right, t), true
+	case "==":
+		return integerConstant(boolToInt(left == right), ast.Int), true
+	case "!=":
+		return integerConstant(boolToInt(left != right), ast.Int), true
+	case "<":
+		return integerConstant(boolToInt(less(left, right, t)), ast.Int), true
+	case ">":
+		return integerConstant(boolToInt(less(right, left, t)), ast.Int), true
+	case "<=":
+		return integerConstant(boolToInt(!less(right, left, t)), ast.Int), true
+	case ">=":
+		return integerConstant(boolToInt(!less(left, right, t)), ast.Int), true
+	}
+	return constant{}, false
+}
+
+// evalFloatOperation - 浮動小数点数には%やビット演算はない
+func evalFloatOperation(operator string, left, right float64, t *ast.Type) (constant, bool) {
+	switch operator {
+	case "+":
+		return floatConstant(left+right, t), true
+	case "-":
+		return floatConstant(left-right, t), true
+	case "*":
+		return floatConstant(left*right, t), true
+	case "/":
+		return floatConstant(left/right, t), true
+	case "==":
+		return integerConstant(boolToInt(left == right), ast.Int), true
+	case "!=":
+		return integerConstant(boolToInt(left != right), ast.Int), true
+	case "<":
+		return integerConstant(boolToInt(left < right), ast.Int), true
+	case ">":
+		return integerConstant(boolToInt(left > right), ast.Int), true
+	case "<=":
+		return integerConstant(boolToInt(left <= right), ast.Int), true
+	case ">=":
+		return integerConstant(boolToInt(left >= right), ast.Int), true
+	}
+	return constant{}, false
+}
+
+func less(left, right int, t *ast.Type) bool {
+	if t.Unsigned {
+		return uint64(left) < uint64(right)
+	}
+	return left < right
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -5,44 +5,91 @@ import (
 	"../lexer"
 	"../token"
 	"fmt"
+	"math"
+	"strings"
 )
 
 const (
 	_ int = iota
 	LOWEST
-	SUM     // +
-	PRODUCT // *
-	PREFIX  // -X or !X
-	CALL    // myFunction(X)
+	ASSIGNMENT  // = or +=
+	CONDITIONAL // ? :
+	LOGICAL_OR  // ||
+	LOGICAL_AND // &&
+	BITWISE_OR  // |
+	BITWISE_XOR // ^
+	BITWISE_AND // &
+	EQUALS      // ==
+	LESSGREATER // > or <
+	SHIFT       // << or >>
+	SUM         // +
+	PRODUCT     // *
+	PREFIX      // -X or !X
+	CALL        // myFunction(X) or X++
 )
 
 var precedences = map[token.TokenType]int{
-	token.ASSIGN:   SUM,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
+	token.ASSIGN:          ASSIGNMENT,
+	token.PLUS_ASSIGN:     ASSIGNMENT,
+	token.MINUS_ASSIGN:    ASSIGNMENT,
+	token.ASTERISK_ASSIGN: ASSIGNMENT,
+	token.SLASH_ASSIGN:    ASSIGNMENT,
+	token.PERCENT_ASSIGN:  ASSIGNMENT,
+	token.QUESTION:        CONDITIONAL,
+	token.OR:              LOGICAL_OR,
+	token.AND:             LOGICAL_AND,
+	token.PIPE:            BITWISE_OR,
+	token.CARET:           BITWISE_XOR,
+	token.AMPERSAND:       BITWISE_AND,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LE:              LESSGREATER,
+	token.GE:              LESSGREATER,
+	token.LSHIFT:          SHIFT,
+	token.RSHIFT:          SHIFT,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.PERCENT:         PRODUCT,
+	token.LPAREN:          CALL,
+	token.LBRACKET:        CALL,
+	token.INCREMENT:       CALL,
+	token.DECREMENT:       CALL,
+	token.DOT:             CALL,
+	token.ARROW:           CALL,
 }
 
 type (
-	infixParseFn func(ast.Expression) ast.Expression
+	prefixParseFn func() ast.Expression
+	infixParseFn  func(ast.Expression) ast.Expression
 )
 
 type Function struct {
-	Name string
-	Argc int
+	Name      string
+	Argc      int
+	Void      bool
+	Prototype *ast.Prototype // 戻り値と仮引数の型を同名の関数と比べる
 }
 
 type Parser struct {
 	l      *lexer.Lexer
 	errors []string
 
-	infixParseFns map[token.TokenType]infixParseFn
-
-	variableTable  []string   // 宣言済みの変数名を登録する
-	prototypeTable []Function // プロトタイプ宣言済みの関数
-	functionTable  []Function // 定義済みの関数
+	prefixParseFns map[token.TokenType]prefixParseFn
+	infixParseFns  map[token.TokenType]infixParseFn
+
+	scope          *scope         // 現在解析中のブロックの変数表
+	globals        *scope         // ファイルスコープの変数表
+	function       *ast.Prototype // 現在解析中の関数
+	loopDepth      int            // 現在解析中のループの入れ子の深さ
+	switchDepth    int            // 現在解析中のswitchの入れ子の深さ
+	labelTable     []string       // 関数内で定義済みのラベル
+	gotoTable      []string       // 関数内でgotoの飛び先として使われたラベル
+	prototypeTable []Function     // プロトタイプ宣言済みの関数
+	functionTable  []Function     // 定義済みの関数
 }
 
 func New(l *lexer.Lexer) *Parser {
@@ -50,18 +97,60 @@ func New(l *lexer.Lexer) *Parser {
 		l:      l,
 		errors: []string{},
 	}
+	p.globals = newScope(nil)
+
+	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
+	p.registerPrefix(token.BANG, p.parsePrefixExpression)
+	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.PLUS, p.parsePrefixExpression)
+	p.registerPrefix(token.TILDE, p.parsePrefixExpression)
+	p.registerPrefix(token.ASTERISK, p.parsePrefixExpression)
+	p.registerPrefix(token.AMPERSAND, p.parsePrefixExpression)
+	p.registerPrefix(token.INCREMENT, p.parsePrefixExpression)
+	p.registerPrefix(token.DECREMENT, p.parsePrefixExpression)
+	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
+	p.registerPrefix(token.STRING, p.parseStringLiteral)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
-	p.registerInfix(token.ASSIGN, p.parseInfixExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.PERCENT_ASSIGN, p.parseAssignmentExpression)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
 	p.registerInfix(token.MINUS, p.parseInfixExpression)
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.AMPERSAND, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.CARET, p.parseInfixExpression)
+	p.registerInfix(token.LSHIFT, p.parseInfixExpression)
+	p.registerInfix(token.RSHIFT, p.parseInfixExpression)
+	p.registerInfix(token.EQ, p.parseInfixExpression)
+	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.LT, p.parseInfixExpression)
+	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LE, p.parseInfixExpression)
+	p.registerInfix(token.GE, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.QUESTION, p.parseConditionalExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.INCREMENT, p.parsePostfixExpression)
+	p.registerInfix(token.DECREMENT, p.parsePostfixExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
+	p.registerInfix(token.ARROW, p.parseMemberExpression)
 
 	return p
 }
 
+func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
+	p.prefixParseFns[tokenType] = fn
+}
+
 func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 	p.infixParseFns[tokenType] = fn
 }
@@ -69,34 +158,42 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 func (p *Parser) Parse() *ast.TranslationUnit {
 	program := &ast.TranslationUnit{}
 
-	// printnum関数を事前定義
-	printnum := ast.Prototype{
-		Token: *token.New(token.INTTYPE, "int", 0),
+	// printnum, printdouble関数を事前定義
+	for _, builtin := range []*ast.Prototype{
+		builtinPrototype("printnum", "i", ast.Int),
+		builtinPrototype("printdouble", "d", ast.Double),
+	} {
+		program.Prototypes = append(program.Prototypes, *builtin)
+		p.prototypeTable = append(p.prototypeTable, Function{builtin.GetName(), 1, false, builtin})
 	}
-	name := &ast.Identifier{
-		Token: *token.New(token.IDENT, "printnum", 0),
-		Value: "printnum",
-	}
-	param := &ast.Identifier{
-		Token: *token.New(token.IDENT, "i", 0),
-		Value: "i",
-	}
-	printnum.Name = name
-	printnum.Parameters = []*ast.Identifier{param}
-	program.Prototypes = append(program.Prototypes, printnum)
-	p.prototypeTable = append(p.prototypeTable, Function{"printnum", 1})
 
 Loop:
 	for {
-		switch p.l.GetCurType() {
-		case token.INTTYPE:
+		switch {
+		case p.isTypeSpecifier():
+			p.scope = p.globals // 関数の外の型名はファイルスコープで探す
+			if !p.isFunctionDeclaration() {
+				// 大域変数
+				for _, decl := range p.parseGlobalDeclaration() {
+					program.Globals = append(program.Globals, *decl)
+				}
+				p.l.GetNextToken() // ; => 次の宣言
+				continue
+			}
+
 			// プロトタイプ宣言
 			prototype := p.parsePrototype()
 
 			// 正当性チェックに使うオブジェクトを作成
 			name := prototype.Name.Name()
 			argc := len(prototype.Parameters)
-			fn := Function{name, argc}
+			fn := Function{name, argc, prototype.IsVoid(), prototype}
+
+			// 同名の関数の宣言や定義と型が異なればエラー
+			if ok := p.checkConflictingTypes(fn); !ok {
+				panicMsg := "conflicting types for " + name
+				panic(panicMsg)
+			}
 
 			// 次が;ならプロトタイプ宣言 {なら関数定義
 			switch p.l.GetCurType() {
@@ -129,7 +226,7 @@ Loop:
 				panic("invalid token")
 
 			}
-		case token.EOF:
+		case p.l.GetCurType() == token.EOF:
 			break Loop
 		default:
 			panic("not prototype or EOF")
@@ -139,11 +236,39 @@ Loop:
 	return program
 }
 
+// builtinPrototype - 実行時ライブラリ(test/printnum.c)の関数 int name(paramType param);
+func builtinPrototype(name, param string, paramType *ast.Type) *ast.Prototype {
+	prototype := &ast.Prototype{
+		Token:      *token.New(token.INTTYPE, "int", 0),
+		ReturnType: ast.Int,
+	}
+	prototype.Name = &ast.Identifier{
+		Token: *token.New(token.IDENT, name, 0),
+		Value: name,
+	}
+	prototype.Parameters = []*ast.Identifier{{
+		Token: *token.New(token.IDENT, param, 0),
+		Value: param,
+	}}
+	prototype.ParameterTypes = []*ast.Type{paramType}
+	return prototype
+}
+
 func (p *Parser) parsePrototype() *ast.Prototype {
 	paramList := []string{}
 
 	prototype := &ast.Prototype{Token: p.l.GetToken()}
 
+	base := p.parseTypeSpecifier()
+	if base.IsVoid() && p.l.GetNextType() == token.ASTERISK {
+		panic("void pointers are not supported")
+	}
+	prototype.ReturnType = p.parsePointers(base)
+	if prototype.ReturnType.IsStructOrUnion() {
+		msg := fmt.Sprintf("%s cannot be returned by value", prototype.ReturnType.String())
+		panic(msg)
+	}
+
 	p.l.GetNextToken() // int => identifier
 
 	if p.l.GetCurType() != token.IDENT {
@@ -157,21 +282,37 @@ func (p *Parser) parsePrototype() *ast.Prototype {
 	}
 	p.l.GetNextToken() // ( => parameter
 
+	// (void)は引数なし
+	if p.l.GetCurType() == token.VOIDTYPE && p.l.GetNextType() == token.RPAREN {
+		p.l.GetNextToken() // void => )
+	}
+
 	// parameter (int a, int b, ...)
 	for {
 		if p.l.GetCurType() == token.RPAREN {
 			p.l.GetNextToken()
 			break
-		} else if p.l.GetCurType() != token.INTTYPE {
+		} else if !p.isTypeSpecifier() {
 			panic("panic")
 		}
 
+		base := p.parseTypeSpecifier()
+		if base.IsVoid() {
+			panic("parameter declared void")
+		}
+		base = p.parsePointers(base)
 		p.l.GetNextToken()
 		identifier := p.parseIdentifier()
 		if contains(paramList, identifier.Token.Literal) {
 			panic("already used")
 		}
+		paramType := p.parseArrayDimensions(base, identifier.Name(), true)
+		if paramType.IsStructOrUnion() {
+			msg := fmt.Sprintf("%s parameter %s must be passed by pointer", paramType.Keyword(), identifier.Name())
+			panic(msg)
+		}
 		prototype.Parameters = append(prototype.Parameters, identifier)
+		prototype.ParameterTypes = append(prototype.ParameterTypes, paramType)
 		paramList = append(paramList, identifier.Token.Literal)
 		p.l.GetNextToken()
 
@@ -194,9 +335,12 @@ func (p *Parser) parseFunctionLiteral(prototype *ast.Prototype) *ast.FunctionLit
 		Token:     p.l.GetToken(),
 		Prototype: *prototype,
 	}
-	p.variableTable = []string{}
+	p.function = prototype
+	p.scope = newScope(p.globals)
+	p.labelTable = []string{}
+	p.gotoTable = []string{}
 	functionLiteral.Body = *p.parseFunctionStatement(prototype)
-	p.functionTable = append(p.functionTable, Function{prototype.GetName(), prototype.GetParamNum()})
+	p.functionTable = append(p.functionTable, Function{prototype.GetName(), prototype.GetParamNum(), prototype.IsVoid(), prototype})
 
 	if p.l.GetCurType() == token.RBRACE {
 		p.l.GetNextToken() // } => 次の関数
@@ -216,34 +360,37 @@ func (p *Parser) parseFunctionStatement(prototype *ast.Prototype) *ast.FunctionS
 		vdecl := &ast.DeclarationStatement{
 			Token: p.l.GetToken(),
 			Name:  *prototype.Parameters[i],
+			Type:  prototype.ParameterTypes[i],
 		}
 		vdecl.SetDeclType(ast.Param)
-		p.variableTable = append(p.variableTable, vdecl.Name.Name())
+		p.declareVariable(vdecl.Name.Name())
 		functionStmt.Declarations = append(functionStmt.Declarations, *vdecl)
 	}
 
 	// parse DeclarationStatements
-	for p.l.GetCurType() == token.INTTYPE {
-		stmt := p.parseDeclarationStatement()
-		if contains(p.variableTable, stmt.Name.Name()) {
-			panic("already declared")
+	for p.isTypeSpecifier() {
+		for _, decl := range p.parseLocalDeclaration() {
+			functionStmt.Declarations = append(functionStmt.Declarations, *decl)
 		}
-		p.variableTable = append(p.variableTable, stmt.Name.Name())
-		functionStmt.Declarations = append(functionStmt.Declarations, *stmt)
 		p.l.GetNextToken()
 	}
 
 	// parse Statements
-	var stmt ast.Statement
 	for p.l.GetCurType() != token.RBRACE {
-		stmt = p.parseStatement()
-		functionStmt.Statements = append(functionStmt.Statements, stmt)
+		functionStmt.Statements = append(functionStmt.Statements, p.parseBlockItem()...)
 		p.l.GetNextToken()
 	}
 
-	// 最後のStatementがReturnか確認
-	if stmt.TokenLiteral() != "return" {
-		panic("not return")
+	// gotoの飛び先が関数内で定義されているか確認
+	if ok, label := p.checkLabels(); !ok {
+		msg := fmt.Sprintf("label %s is not defined", label)
+		panic(msg)
+	}
+
+	// 値を返す関数は末尾に到達してはいけない mainは暗黙に0を返す
+	if !prototype.IsVoid() && prototype.GetName() != "main" && completesAll(functionStmt.Statements) {
+		msg := fmt.Sprintf("control reaches end of non-void function %s", prototype.GetName())
+		panic(msg)
 	}
 
 	return functionStmt
@@ -257,41 +404,670 @@ func (p *Parser) parseIdentifier() *ast.Identifier {
 	return identifier
 }
 
-func (p *Parser) parseDeclarationStatement() *ast.DeclarationStatement {
-	declarationStatement := &ast.DeclarationStatement{
-		Token: p.l.GetToken(),
+// parseDeclarationStatement - int a = 1, b; を宣言子ごとのDeclarationStatementに分ける
+func (p *Parser) parseDeclarationStatement() []*ast.DeclarationStatement {
+	typeToken := p.l.GetToken()
+	baseType := p.parseTypeSpecifier()
+	if baseType.IsVoid() {
+		panic("variable declared void")
+	}
+
+	declarations := []*ast.DeclarationStatement{}
+
+	// struct point { ... }; やunion value; は型だけを宣言する
+	if baseType.IsStructOrUnion() && p.l.GetNextType() == token.SEMICOLON {
+		p.l.GetNextToken() // => ;
+		return declarations
+	}
+
+	for {
+		declarationStatement := &ast.DeclarationStatement{
+			Token: typeToken,
+		}
+		declarationStatement.SetDeclType(ast.Local)
+		base := p.parsePointers(baseType)
+		p.expectNext(token.IDENT) // type or , or * => identifer
+		declarationStatement.Name = *p.parseIdentifier()
+		declarationStatement.Type = p.parseArrayDimensions(base, declarationStatement.Name.Name(), false)
+		if !declarationStatement.Type.IsComplete() {
+			msg := fmt.Sprintf("storage size of %s is not known", declarationStatement.Name.Name())
+			panic(msg)
+		}
+
+		if p.l.GetNextType() == token.ASSIGN {
+			if declarationStatement.Type.IsArray() {
+				msg := fmt.Sprintf("array %s cannot be initialized with an expression", declarationStatement.Name.Name())
+				panic(msg)
+			}
+			p.l.GetNextToken() // identifer => =
+			p.l.GetNextToken() // = => expression
+			declarationStatement.Value = p.parseExpression(LOWEST)
+		}
+		declarations = append(declarations, declarationStatement)
+
+		if p.l.GetNextType() != token.COMMA {
+			break
+		}
+		p.l.GetNextToken() // => ,
+	}
+
+	p.expectNext(token.SEMICOLON) // => ;
+	return declarations
+}
+
+// isTypeSpecifier - 宣言の先頭になる型名か
+func (p *Parser) isTypeSpecifier() bool {
+	return isTypeSpecifierToken(p.l.GetCurType())
+}
+
+func isTypeSpecifierToken(t token.TokenType) bool {
+	switch t {
+	case token.INTTYPE, token.CHARTYPE, token.SHORTTYPE, token.LONGTYPE,
+		token.SIGNED, token.UNSIGNED, token.FLOATTYPE, token.DOUBLETYPE, token.VOIDTYPE, token.STRUCT, token.UNION:
+		return true
+	}
+	return false
+}
+
+// parseTypeSpecifier - 型名に対応する型 現在のトークンは型名の最後のトークンになる
+// unsigned long intのように複数の単語からなる型名は順不同で読む
+func (p *Parser) parseTypeSpecifier() *ast.Type {
+	if p.l.GetCurType() == token.STRUCT || p.l.GetCurType() == token.UNION {
+		return p.parseStructOrUnionSpecifier()
+	}
+
+	words := []string{p.l.GetCurString()}
+	for isTypeSpecifierToken(p.l.GetNextType()) {
+		p.l.GetNextToken() // => 型名の次の単語
+		words = append(words, p.l.GetCurString())
+	}
+
+	count := map[string]int{}
+	for _, word := range words {
+		count[word]++
+	}
+
+	// 型を決める単語と、一緒に使える単語 signed/unsignedだけならint
+	kind, allowed := ast.IntType, "signed unsigned int"
+	switch {
+	case count["void"] > 0:
+		kind, allowed = ast.VoidType, "void"
+	case count["float"] > 0:
+		kind, allowed = ast.FloatType, "float"
+	case count["double"] > 0:
+		kind, allowed = ast.DoubleType, "double"
+	case count["char"] > 0:
+		kind, allowed = ast.CharType, "signed unsigned char"
+	case count["short"] > 0:
+		kind, allowed = ast.ShortType, "signed unsigned short int"
+	case count["long"] > 1:
+		kind, allowed = ast.LongLongType, "signed unsigned long int"
+	case count["long"] > 0:
+		kind, allowed = ast.LongType, "signed unsigned long int"
+	}
+
+	for word, n := range count {
+		if !strings.Contains(" "+allowed+" ", " "+word+" ") || n > 1 && !(word == "long" && n == 2) {
+			msg := fmt.Sprintf("invalid type specifier %s", strings.Join(words, " "))
+			panic(msg)
+		}
+	}
+	if count["signed"] > 0 && count["unsigned"] > 0 {
+		msg := fmt.Sprintf("invalid type specifier %s", strings.Join(words, " "))
+		panic(msg)
+	}
+
+	switch kind {
+	case ast.VoidType:
+		return ast.Void
+	case ast.FloatType:
+		return ast.Float
+	case ast.DoubleType:
+		return ast.Double
+	}
+	return ast.Integer(kind, count["unsigned"] > 0)
+}
+
+// parseStructOrUnionSpecifier - struct タグ { メンバの宣言 } やunion タグ { ... } を読む 現在のトークンはタグか}になる
+// タグだけなら宣言済みの型 まだ宣言されていなければ不完全型として現在のスコープに登録する
+func (p *Parser) parseStructOrUnionSpecifier() *ast.Type {
+	keyword, kind := p.l.GetCurString(), ast.StructType
+	if p.l.GetCurType() == token.UNION {
+		kind = ast.UnionType
+	}
+
+	tag := ""
+	if p.l.GetNextType() == token.IDENT {
+		p.l.GetNextToken() // struct => タグ
+		tag = p.l.GetCurString()
+	}
+
+	if p.l.GetNextType() != token.LBRACE {
+		if tag == "" {
+			msg := fmt.Sprintf("%s has neither a tag nor members", keyword)
+			panic(msg)
+		}
+		// struct タグ; は外側に同じタグがあっても新しい型を宣言する
+		if t := p.scope.lookupTag(tag); t != nil && p.l.GetNextType() != token.SEMICOLON {
+			checkTagKind(t, kind)
+			return t
+		}
+		return p.declareTag(kind, tag)
+	}
+
+	t := newTaggedType(kind, tag)
+	if tag != "" {
+		t = p.declareTag(kind, tag)
+		if t.IsComplete() {
+			msg := fmt.Sprintf("redefinition of %s %s", keyword, tag)
+			panic(msg)
+		}
+	}
+
+	p.l.GetNextToken() // => {
+	p.l.GetNextToken() // { => メンバの型
+	members := []*ast.Member{}
+	for p.l.GetCurType() != token.RBRACE {
+		members = append(members, p.parseMemberDeclaration(members)...)
+		p.l.GetNextToken() // ; => 次のメンバの型か}
+	}
+	if len(members) == 0 {
+		msg := fmt.Sprintf("%s has no members", t.String())
+		panic(msg)
+	}
+
+	// メンバを読み終えてから完全型になる 自身を指すポインタはメンバにできる
+	t.DefineMembers(members)
+	return t
+}
+
+// parseMemberDeclaration - int x, *next; のようなメンバの宣言を読む 現在のトークンは;になる
+// declaredは同じ構造体で宣言済みのメンバ
+func (p *Parser) parseMemberDeclaration(declared []*ast.Member) []*ast.Member {
+	if !p.isTypeSpecifier() {
+		msg := fmt.Sprintf("invalid member declaration %s", p.l.GetCurString())
+		panic(msg)
+	}
+
+	baseType := p.parseTypeSpecifier()
+	if baseType.IsVoid() {
+		panic("member declared void")
 	}
-	declarationStatement.SetDeclType(ast.Local)
-	p.l.GetNextToken() // INTTYPE => identifer
 
-	declarationStatement.Name = *p.parseIdentifier()
+	members := []*ast.Member{}
+	for {
+		base := p.parsePointers(baseType)
+		p.expectNext(token.IDENT) // type or , or * => identifer
+		name := p.l.GetCurString()
+		t := p.parseArrayDimensions(base, name, false)
+		if !t.IsComplete() {
+			msg := fmt.Sprintf("member %s has incomplete type", name)
+			panic(msg)
+		}
+		if hasMember(declared, name) || hasMember(members, name) {
+			msg := fmt.Sprintf("duplicate member %s", name)
+			panic(msg)
+		}
+		members = append(members, &ast.Member{Name: name, Type: t})
+
+		if p.l.GetNextType() != token.COMMA {
+			break
+		}
+		p.l.GetNextToken() // => ,
+	}
+
+	p.expectNext(token.SEMICOLON) // => ;
+	return members
+}
+
+// parsePointers - 名前の前の*を読んでbaseへのポインタ型を作る 現在のトークンは最後の*になる
+func (p *Parser) parsePointers(base *ast.Type) *ast.Type {
+	t := base
+	for p.l.GetNextType() == token.ASTERISK {
+		p.l.GetNextToken() // => *
+		t = ast.PointerTo(t)
+	}
+	return t
+}
+
+// parseArrayDimensions - 名前の後ろの[N]...を読んで型を作る 現在のトークンは最後の]になる
+// 仮引数の配列は先頭の要素を指すポインタとして扱うので、最初の要素数は省略できる
+func (p *Parser) parseArrayDimensions(base *ast.Type, name string, param bool) *ast.Type {
+	lengths := []int{}
+	for p.l.GetNextType() == token.LBRACKET {
+		p.l.GetNextToken() // => [
+
+		if param && len(lengths) == 0 && p.l.GetNextType() == token.RBRACKET {
+			p.l.GetNextToken() // [ => ]
+			lengths = append(lengths, 0)
+			continue
+		}
+
+		p.l.GetNextToken() // [ => expression
+		length, ok := evalConstant(p.parseExpression(LOWEST))
+		if !ok || length <= 0 {
+			msg := fmt.Sprintf("size of array %s must be a positive constant", name)
+			panic(msg)
+		}
+		lengths = append(lengths, length)
+		p.expectNext(token.RBRACKET) // expression => ]
+	}
+
+	if len(lengths) == 0 {
+		return base
+	}
+
+	// a[2][3]は「intの3要素の配列」の2要素の配列
+	t := base
+	for i := len(lengths) - 1; i > 0; i-- {
+		t = ast.ArrayOf(t, lengths[i])
+	}
+	if param {
+		return ast.PointerTo(t)
+	}
+	return ast.ArrayOf(t, lengths[0])
+}
+
+// isFunctionDeclaration - int identifier ( ならプロトタイプ宣言か関数定義
+func (p *Parser) isFunctionDeclaration() bool {
+	index := p.l.GetCurIndex()
+	defer p.l.ApplyTokenIndex(index)
+
+	// struct タグ { は構造体の定義 共用体も同じ
+	if p.l.GetCurType() == token.STRUCT || p.l.GetCurType() == token.UNION {
+		if p.l.GetNextType() != token.IDENT {
+			return false
+		}
+		p.l.GetNextToken() // struct => タグ
+		if p.l.GetNextType() == token.LBRACE {
+			return false
+		}
+	}
+
+	for isTypeSpecifierToken(p.l.GetNextType()) {
+		p.l.GetNextToken() // => 型名の次の単語
+	}
+	p.l.GetNextToken() // 型名 => identifier
+	for p.l.GetCurType() == token.ASTERISK {
+		p.l.GetNextToken() // * => identifier
+	}
+	return p.l.GetNextType() == token.LPAREN
+}
+
+// parseGlobalDeclaration - 大域変数の初期化式はコンパイル時に評価できなければならない
+func (p *Parser) parseGlobalDeclaration() []*ast.DeclarationStatement {
+	p.scope = p.globals
+	declarations := p.parseDeclarationStatement()
+	for _, decl := range declarations {
+		decl.SetDeclType(ast.Global)
+		p.declareVariable(decl.Name.Name())
+
+		if decl.Value != nil {
+			// 構造体や共用体の定数式はないので、大域の構造体や共用体は初期化できない
+			if decl.Type.IsStructOrUnion() {
+				msg := fmt.Sprintf("%s %s cannot be initialized with an expression", decl.Type.Keyword(), decl.Name.Name())
+				panic(msg)
+			}
+
+			value, ok := evalTypedConstant(decl.Value)
+			if !ok {
+				msg := fmt.Sprintf("initializer of %s is not a constant expression", decl.Name.Name())
+				panic(msg)
+			}
+
+			// ポインタを初期化できる定数はヌルポインタだけ
+			if decl.Type.IsPointer() && (!value.t.IsInteger() || value.intValue != 0) {
+				msg := fmt.Sprintf("initializer of pointer %s is not a null pointer constant", decl.Name.Name())
+				panic(msg)
+			}
+
+			// 変数の型に変換した値
+			if decl.Type.IsArithmetic() {
+				value = value.convertTo(decl.Type)
+			}
+			decl.Constant, decl.FloatConstant = value.intValue, value.floatValue
+		}
+	}
+	return declarations
+}
 
-	for p.l.GetNextType() == token.SEMICOLON {
-		p.l.GetNextToken() // identifer => semicolon
+// parseLocalDeclaration - ブロック内の宣言を解析して現在のスコープに登録する
+func (p *Parser) parseLocalDeclaration() []*ast.DeclarationStatement {
+	declarations := p.parseDeclarationStatement()
+	for _, decl := range declarations {
+		p.declareVariable(decl.Name.Name())
 	}
-	return declarationStatement
+	return declarations
+}
+
+// parseBlockItem - ブロック内では宣言と文を混在できる 宣言は宣言子ごとの文になる
+func (p *Parser) parseBlockItem() []ast.Statement {
+	if p.isTypeSpecifier() {
+		stmts := []ast.Statement{}
+		for _, decl := range p.parseLocalDeclaration() {
+			stmts = append(stmts, decl)
+		}
+		return stmts
+	}
+	return []ast.Statement{p.parseStatement()}
 }
 
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.l.GetCurType() {
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.IF:
+		return p.parseIfStatement()
+	case token.LBRACE:
+		return p.parseBlockStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.DO:
+		return p.parseDoWhileStatement()
+	case token.SWITCH:
+		return p.parseSwitchStatement()
+	case token.GOTO:
+		return p.parseGotoStatement()
+	case token.IDENT:
+		if p.l.GetNextType() == token.COLON {
+			return p.parseLabeledStatement()
+		}
+		return p.parseExpressionStatement()
+	case token.SEMICOLON:
+		// 空文
+		return &ast.ExpressionStatement{Token: p.l.GetToken()}
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
+func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+	block := &ast.BlockStatement{
+		Token: p.l.GetToken(),
+	}
+
+	p.l.GetNextToken() // { => statement
+
+	p.openScope()
+	for p.l.GetCurType() != token.RBRACE {
+		if p.l.GetCurType() == token.EOF {
+			panic("block is not closed")
+		}
+		block.Statements = append(block.Statements, p.parseBlockItem()...)
+		p.l.GetNextToken()
+	}
+	p.closeScope()
+
+	return block
+}
+
+func (p *Parser) parseIfStatement() *ast.IfStatement {
+	stmt := &ast.IfStatement{
+		Token: p.l.GetToken(),
+	}
+
+	p.expectNext(token.LPAREN) // if => (
+	p.l.GetNextToken()         // ( => expression
+	stmt.Condition = p.parseExpression(LOWEST)
+	p.expectNext(token.RPAREN) // expression => )
+	p.l.GetNextToken()         // ) => statement
+	stmt.Consequence = p.parseStatement()
+
+	if p.l.GetNextType() == token.ELSE {
+		p.l.GetNextToken() // statement => else
+		p.l.GetNextToken() // else => statement
+		stmt.Alternative = p.parseStatement()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+	stmt := &ast.WhileStatement{
+		Token: p.l.GetToken(),
+	}
+
+	p.expectNext(token.LPAREN) // while => (
+	p.l.GetNextToken()         // ( => expression
+	stmt.Condition = p.parseExpression(LOWEST)
+	p.expectNext(token.RPAREN) // expression => )
+	p.l.GetNextToken()         // ) => statement
+
+	p.loopDepth++
+	stmt.Body = p.parseStatement()
+	p.loopDepth--
+
+	return stmt
+}
+
+func (p *Parser) parseForStatement() *ast.ForStatement {
+	stmt := &ast.ForStatement{
+		Token: p.l.GetToken(),
+	}
+
+	p.expectNext(token.LPAREN) // for => (
+
+	// 初期化節の宣言はループの中だけで見える 本体のブロックはさらに内側のスコープ
+	p.openScope()
+	if isTypeSpecifierToken(p.l.GetNextType()) {
+		p.l.GetNextToken() // ( => type
+		stmt.Declarations = p.parseLocalDeclaration()
+	} else {
+		stmt.Init = p.parseOptionalExpression(token.SEMICOLON)
+	}
+	stmt.Condition = p.parseOptionalExpression(token.SEMICOLON)
+	stmt.Step = p.parseOptionalExpression(token.RPAREN)
+	p.l.GetNextToken() // ) => statement
+
+	p.loopDepth++
+	stmt.Body = p.parseStatement()
+	p.loopDepth--
+	p.closeScope()
+
+	return stmt
+}
+
+func (p *Parser) parseDoWhileStatement() *ast.DoWhileStatement {
+	stmt := &ast.DoWhileStatement{
+		Token: p.l.GetToken(),
+	}
+
+	p.l.GetNextToken() // do => statement
+	p.loopDepth++
+	stmt.Body = p.parseStatement()
+	p.loopDepth--
+
+	p.expectNext(token.WHILE)  // statement => while
+	p.expectNext(token.LPAREN) // while => (
+	p.l.GetNextToken()         // ( => expression
+	stmt.Condition = p.parseExpression(LOWEST)
+	p.expectNext(token.RPAREN)    // expression => )
+	p.expectNext(token.SEMICOLON) // ) => ;
+
+	return stmt
+}
+
+func (p *Parser) parseSwitchStatement() *ast.SwitchStatement {
+	stmt := &ast.SwitchStatement{
+		Token: p.l.GetToken(),
+	}
+
+	p.expectNext(token.LPAREN) // switch => (
+	p.l.GetNextToken()         // ( => expression
+	stmt.Tag = p.parseExpression(LOWEST)
+	p.expectNext(token.RPAREN) // expression => )
+	p.expectNext(token.LBRACE) // ) => {
+	p.l.GetNextToken()         // { => case
+
+	p.switchDepth++
+	p.openScope()
+	values := map[int]bool{}
+	hasDefault := false
+	for p.l.GetCurType() != token.RBRACE {
+		clause := p.parseCaseClause()
+
+		// 重複したラベルはエラー 制御式の型に変換すると重なる値は生成時に調べる
+		if clause.IsDefault() {
+			if hasDefault {
+				panic("multiple default labels in one switch")
+			}
+			hasDefault = true
+		} else {
+			if values[clause.Constant] {
+				msg := fmt.Sprintf("duplicate case value %d", clause.Constant)
+				panic(msg)
+			}
+			values[clause.Constant] = true
+		}
+
+		stmt.Cases = append(stmt.Cases, clause)
+	}
+	p.closeScope()
+	p.switchDepth--
+
+	return stmt
+}
+
+func (p *Parser) parseCaseClause() *ast.CaseClause {
+	clause := &ast.CaseClause{
+		Token: p.l.GetToken(),
+	}
+
+	switch p.l.GetCurType() {
+	case token.CASE:
+		p.l.GetNextToken() // case => expression
+		clause.Value = p.parseExpression(LOWEST)
+		value, ok := evalConstant(clause.Value)
+		if !ok {
+			msg := fmt.Sprintf("case label %s is not a constant expression", clause.Value.String())
+			panic(msg)
+		}
+		clause.Constant = value
+	case token.DEFAULT:
+	default:
+		msg := fmt.Sprintf("expected case or default, got %s", p.l.GetCurType())
+		panic(msg)
+	}
+	p.expectNext(token.COLON) // => :
+	p.l.GetNextToken()        // : => statement
+
+	// 次のラベルかswitchの終わりまでが1つの節
+	for p.l.GetCurType() != token.CASE && p.l.GetCurType() != token.DEFAULT && p.l.GetCurType() != token.RBRACE {
+		if p.l.GetCurType() == token.EOF {
+			panic("switch is not closed")
+		}
+		clause.Statements = append(clause.Statements, p.parseBlockItem()...)
+		p.l.GetNextToken()
+	}
+
+	return clause
+}
+
+// parseOptionalExpression - 次のトークンからendまでの式を解析する 式が省略されていればnilを返す
+func (p *Parser) parseOptionalExpression(end token.TokenType) ast.Expression {
+	if p.l.GetNextType() == end {
+		p.l.GetNextToken() // => end
+		return nil
+	}
+
+	p.l.GetNextToken() // => expression
+	exp := p.parseExpression(LOWEST)
+	p.expectNext(end) // expression => end
+
+	return exp
+}
+
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmt := &ast.BreakStatement{
+		Token: p.l.GetToken(),
+	}
+
+	if p.loopDepth == 0 && p.switchDepth == 0 {
+		panic("break statement not within loop or switch")
+	}
+	p.expectNext(token.SEMICOLON) // break => ;
+
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmt := &ast.ContinueStatement{
+		Token: p.l.GetToken(),
+	}
+
+	if p.loopDepth == 0 {
+		panic("continue statement not within loop")
+	}
+	p.expectNext(token.SEMICOLON) // continue => ;
+
+	return stmt
+}
+
+func (p *Parser) parseLabeledStatement() *ast.LabeledStatement {
+	stmt := &ast.LabeledStatement{
+		Token: p.l.GetToken(),
+		Label: p.parseIdentifier(),
+	}
+
+	// ラベル名は関数内で一意
+	if contains(p.labelTable, stmt.Label.Name()) {
+		msg := fmt.Sprintf("label %s is already defined", stmt.Label.Name())
+		panic(msg)
+	}
+	p.labelTable = append(p.labelTable, stmt.Label.Name())
+
+	p.l.GetNextToken() // identifier => :
+	p.l.GetNextToken() // : => statement
+	stmt.Statement = p.parseStatement()
+
+	return stmt
+}
+
+func (p *Parser) parseGotoStatement() *ast.GotoStatement {
+	stmt := &ast.GotoStatement{
+		Token: p.l.GetToken(),
+	}
+
+	p.expectNext(token.IDENT) // goto => identifier
+	stmt.Label = p.parseIdentifier()
+	p.expectNext(token.SEMICOLON) // identifier => ;
+
+	// 後方で定義されるラベルもあるので、存在の確認は関数の終わりで行う
+	p.gotoTable = append(p.gotoTable, stmt.Label.Name())
+
+	return stmt
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{
 		Token: p.l.GetToken(),
 	}
 
-	p.l.GetNextToken() // return => expression
+	if p.l.GetNextType() == token.SEMICOLON {
+		// 値を返さないreturn
+		p.l.GetNextToken() // return => ;
+	} else {
+		p.l.GetNextToken() // return => expression
+		stmt.ReturnValue = p.parseExpression(LOWEST)
 
-	stmt.ReturnValue = p.parseExpression(LOWEST)
+		if p.l.GetNextType() == token.SEMICOLON {
+			p.l.GetNextToken()
+		}
+	}
 
-	if p.l.GetNextType() == token.SEMICOLON {
-		p.l.GetNextToken()
+	if p.function.IsVoid() && stmt.ReturnValue != nil {
+		msg := fmt.Sprintf("void function %s should not return a value", p.function.GetName())
+		panic(msg)
+	}
+	if !p.function.IsVoid() && stmt.ReturnValue == nil {
+		msg := fmt.Sprintf("non-void function %s should return a value", p.function.GetName())
+		panic(msg)
 	}
 
 	return stmt
@@ -316,8 +1092,18 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	switch p.l.GetCurType() {
 	case token.IDENT:
 		exp = p.parseIdentifier()
-	case token.DIGIT:
+	case token.DIGIT, token.CHARACTER:
+		// 文字定数はint型の定数
 		exp = p.parseNumber()
+	case token.FLOATING:
+		exp = p.parseFloatLiteral()
+	default:
+		prefix := p.prefixParseFns[p.l.GetCurType()]
+		if prefix == nil {
+			msg := fmt.Sprintf("unexpected token %s in expression", p.l.GetCurString())
+			panic(msg)
+		}
+		exp = prefix()
 	}
 
 	for p.l.GetNextType() != token.SEMICOLON && precedence < p.peekPrecedence() {
@@ -348,14 +1134,107 @@ func (p *Parser) peekPrecedence() int {
 	return LOWEST
 }
 
+// parseStringLiteral - 隣り合った文字列は連結する
+func (p *Parser) parseStringLiteral() ast.Expression {
+	str := &ast.StringLiteral{
+		Token: p.l.GetToken(),
+		Value: p.l.GetCurString(),
+	}
+
+	for p.l.GetNextType() == token.STRING {
+		p.l.GetNextToken() // string => string
+		str.Value += p.l.GetCurString()
+	}
+	return str
+}
+
 func (p *Parser) parseNumber() *ast.Number {
 	number := &ast.Number{
 		Token: p.l.GetToken(),
 		Value: p.l.GetCurNumVal(),
+		Type:  ast.Int,
+	}
+	if number.Token.Type == token.DIGIT {
+		number.Type = numberType(number.Token.Literal, number.Value)
 	}
 	return number
 }
 
+// parseFloatLiteral - 接尾辞fがあればfloat、なければdouble
+func (p *Parser) parseFloatLiteral() *ast.FloatLiteral {
+	literal := &ast.FloatLiteral{
+		Token: p.l.GetToken(),
+		Type:  ast.Double,
+	}
+	literal.Value = literal.Token.Float
+	if strings.HasSuffix(literal.Token.Literal, "f") || strings.HasSuffix(literal.Token.Literal, "F") {
+		literal.Type = ast.Float
+	}
+	return literal
+}
+
+// numberType - 整数定数の型 接尾辞の型で表せなければより大きい型にする
+func numberType(literal string, value int) *ast.Type {
+	suffix := strings.TrimLeft(literal, "0123456789")
+
+	unsigned := false
+	if strings.HasPrefix(suffix, "u") || strings.HasPrefix(suffix, "U") {
+		unsigned, suffix = true, suffix[1:]
+	} else if strings.HasSuffix(suffix, "u") || strings.HasSuffix(suffix, "U") {
+		unsigned, suffix = true, suffix[:len(suffix)-1]
+	}
+
+	kind := ast.IntType
+	switch suffix {
+	case "":
+	case "l", "L":
+		kind = ast.LongType
+	case "ll", "LL":
+		kind = ast.LongLongType
+	default:
+		msg := fmt.Sprintf("invalid suffix on integer constant %s", literal)
+		panic(msg)
+	}
+
+	// 接尾辞のない10進数はunsignedにならない ただしlongに収まらない値はgccと同じくunsigned long
+	bits := uint64(value)
+	if kind == ast.IntType && (bits > math.MaxUint32 || bits > math.MaxInt32 && !unsigned) {
+		kind = ast.LongType
+	}
+	if bits > math.MaxInt64 {
+		unsigned = true
+	}
+	return ast.Integer(kind, unsigned)
+}
+
+func (p *Parser) parseGroupedExpression() ast.Expression {
+	expression := &ast.GroupedExpression{
+		Token: p.l.GetToken(),
+	}
+
+	p.l.GetNextToken() // ( => expression
+	expression.Expression = p.parseExpression(LOWEST)
+	p.expectNext(token.RPAREN) // expression => )
+
+	return expression
+}
+
+func (p *Parser) parsePrefixExpression() ast.Expression {
+	expression := &ast.PrefixExpression{
+		Token:    p.l.GetToken(),
+		Operator: p.l.GetCurString(),
+	}
+
+	p.l.GetNextToken() // operator => expression
+	expression.Right = p.parseExpression(PREFIX)
+
+	// アドレスを取れるのは左辺値だけ
+	if expression.Operator == "++" || expression.Operator == "--" || expression.Operator == "&" {
+		p.checkLvalue(expression.Right)
+	}
+	return expression
+}
+
 func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	expression := &ast.InfixExpression{
 		Token:    p.l.GetToken(),
@@ -369,6 +1248,72 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseAssignmentExpression(left ast.Expression) ast.Expression {
+	expression := &ast.InfixExpression{
+		Token:    p.l.GetToken(),
+		Operator: p.l.GetCurString(),
+		Left:     left,
+	}
+	p.checkLvalue(left)
+
+	// 右結合にするため、同じ優先順位の代入も右辺に含める
+	p.l.GetNextToken() // operator => expression
+	expression.Right = p.parseExpression(ASSIGNMENT - 1)
+	return expression
+}
+
+func (p *Parser) parseConditionalExpression(condition ast.Expression) ast.Expression {
+	expression := &ast.ConditionalExpression{
+		Token:     p.l.GetToken(),
+		Condition: condition,
+	}
+
+	p.l.GetNextToken() // ? => expression
+	expression.Consequence = p.parseExpression(LOWEST)
+	p.expectNext(token.COLON) // expression => :
+	p.l.GetNextToken()        // : => expression
+
+	// 右結合にするため、同じ優先順位の?:も右辺に含める
+	expression.Alternative = p.parseExpression(CONDITIONAL - 1)
+	return expression
+}
+
+func (p *Parser) parsePostfixExpression(left ast.Expression) ast.Expression {
+	p.checkLvalue(left)
+
+	return &ast.PostfixExpression{
+		Token:    p.l.GetToken(),
+		Operator: p.l.GetCurString(),
+		Left:     left,
+	}
+}
+
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	expr := &ast.IndexExpression{
+		Token: p.l.GetToken(),
+		Left:  left,
+	}
+
+	p.l.GetNextToken() // [ => expression
+	expr.Index = p.parseExpression(LOWEST)
+	p.expectNext(token.RBRACKET) // expression => ]
+
+	return expr
+}
+
+// parseMemberExpression - s.x, p->x メンバが構造体にあるかはコード生成で調べる
+func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	expr := &ast.MemberExpression{
+		Token: p.l.GetToken(),
+		Left:  left,
+	}
+
+	p.expectNext(token.IDENT) // . => member
+	expr.Member = p.parseIdentifier()
+
+	return expr
+}
+
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	call := &ast.CallExpression{
 		Token:    p.l.GetToken(),
@@ -435,6 +1380,15 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	return list
 }
 
+// expectNext - 次のトークンが期待した種類であれば読み進め、そうでなければpanicする
+func (p *Parser) expectNext(tokenType token.TokenType) {
+	if p.l.GetNextType() != tokenType {
+		msg := fmt.Sprintf("expected %s, got %s", tokenType, p.l.GetNextType())
+		panic(msg)
+	}
+	p.l.GetNextToken()
+}
+
 func contains(slice []string, target string) bool {
 	for _, s := range slice {
 		if target == s {
@@ -443,3 +1397,12 @@ func contains(slice []string, target string) bool {
 	}
 	return false
 }
+
+func hasMember(members []*ast.Member, name string) bool {
+	for _, member := range members {
+		if member.Name == name {
+			return true
+		}
+	}
+	return false
+}
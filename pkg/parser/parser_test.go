@@ -10,7 +10,7 @@ import (
 func TestParse(t *testing.T) {
 	input, err := readFile("../../test/test.dc")
 	if err != nil {
-		t.Fatalf(err.Error())
+		t.Fatal(err)
 	}
 
 	l := lexer.New(input)
@@ -45,6 +45,802 @@ func TestDeclarationStatement(t *testing.T) {
 	}
 }
 
+func TestDeclarationWithInitializer(t *testing.T) {
+	input := `int main(int n) {
+		int a = 1, b, c = a * 2;
+		b = c;
+		int d = b = n + 1;
+		return d;
+	}
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+	function := translationUnit.Functions[0]
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"n", ""},
+		{"a", "1"},
+		{"b", ""},
+		{"c", "(a * 2)"},
+	}
+
+	declarations := function.Body.Declarations
+	if len(declarations) != len(tests) {
+		t.Fatalf("declarations does not contain %d declarations. got=%d\n", len(tests), len(declarations))
+	}
+	for i, tt := range tests {
+		decl := declarations[i]
+		if decl.Name.Name() != tt.name {
+			t.Errorf("declarations[%d] - name is not %s. got=%s", i, tt.name, decl.Name.Name())
+		}
+		value := ""
+		if decl.Value != nil {
+			value = decl.Value.String()
+		}
+		if value != tt.value {
+			t.Errorf("declarations[%d] - initializer is not %q. got=%q", i, tt.value, value)
+		}
+	}
+
+	decl, ok := function.Body.Statements[1].(*ast.DeclarationStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.DeclarationStatement. got=%T", function.Body.Statements[1])
+	}
+	if decl.String() != "int d = (b = (n + 1));" {
+		t.Fatalf("declaration is not %q. got=%q", "int d = (b = (n + 1));", decl.String())
+	}
+}
+
+func TestGlobalDeclaration(t *testing.T) {
+	input := `int counter = 0;
+	int limit = (1 << 4) - 1, total;
+	int main() {
+		counter = counter + 1;
+		return limit;
+	}
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	tests := []struct {
+		name     string
+		constant int
+	}{
+		{"counter", 0},
+		{"limit", 15},
+		{"total", 0},
+	}
+
+	if len(translationUnit.Globals) != len(tests) {
+		t.Fatalf("globals does not contain %d declarations. got=%d\n", len(tests), len(translationUnit.Globals))
+	}
+	for i, tt := range tests {
+		global := translationUnit.Globals[i]
+		if global.Name.Name() != tt.name {
+			t.Errorf("globals[%d] - name is not %s. got=%s", i, tt.name, global.Name.Name())
+		}
+		if global.GetDeclType() != ast.Global {
+			t.Errorf("globals[%d] - declType is not %s. got=%s", i, ast.Global, global.GetDeclType())
+		}
+		if global.Constant != tt.constant {
+			t.Errorf("globals[%d] - constant is not %d. got=%d", i, tt.constant, global.Constant)
+		}
+	}
+
+	if len(translationUnit.Functions) != 1 {
+		t.Fatalf("functions does not contain %d functions. got=%d\n", 1, len(translationUnit.Functions))
+	}
+}
+
+func TestTypedConstantExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		constant int
+	}{
+		{"int g = 2147483647 + 1;", -2147483648},
+		{"long g = 2147483647 + 1L;", 2147483648},
+		{"unsigned g = -1;", 4294967295},
+		{"unsigned char g = 300;", 44},
+		{"signed char g = 200;", -56},
+		{"int g = -1 < 1u;", 0},
+		{"int g = -1 < 1L;", 1},
+		{"int g = -1L < 1u;", 1},
+		{"unsigned g = 4294967295u / 2;", 2147483647},
+		{"int g = -8 >> 1;", -4},
+		{"unsigned g = -8u >> 1;", 2147483644},
+		{"long g = 1L << 40;", 1 << 40},
+		{"unsigned long g = -1;", -1},
+		{"long g = 1 ? 1 : 2u;", 1},
+		{"long g = 1 ? -1 : 2u;", 4294967295},
+		{"int g = 18446744073709551615;", -1},
+		{"unsigned long long g = 18446744073709551615ULL;", -1},
+		{"int g = 18446744073709551615ULL > 9223372036854775808u;", 1},
+		{"long g = 9223372036854775808u / 2;", 1 << 62},
+	}
+
+	for i, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		translationUnit := p.Parse()
+
+		global := translationUnit.Globals[0]
+		if global.Constant != tt.constant {
+			t.Errorf("tests[%d] - constant of %q is not %d. got=%d", i, tt.input, tt.constant, global.Constant)
+		}
+	}
+}
+
+func TestInvalidGlobalDeclaration(t *testing.T) {
+	tests := []string{
+		"int a = 1;\nint b = a;",
+		"int f(int x);\nint a = f(1);",
+		"int a = 1, a = 2;",
+		"int a;\nint a;",
+		"int a = 1",
+	}
+
+	for i, tt := range tests {
+		input := tt + "\nint main() {\nreturn 0;\n}"
+		expectPanic(t, i, input)
+	}
+}
+
+func TestArrayDeclaration(t *testing.T) {
+	input := `int table[4];
+	int sum(int v[], int m[][3], int n) {
+		int a[10], b, c[2][3];
+		a[0] = v[n - 1];
+		c[1][2] = m[0][1];
+		return a[0] + c[1][2];
+	}
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	if translationUnit.Globals[0].String() != "int table[4];" {
+		t.Errorf("global is not %q. got=%q", "int table[4];", translationUnit.Globals[0].String())
+	}
+
+	tests := []struct {
+		name string
+		typ  string
+	}{
+		{"v", "int *"},
+		{"m", "int (*)[3]"},
+		{"n", "int"},
+		{"a", "int [10]"},
+		{"b", "int"},
+		{"c", "int [2][3]"},
+	}
+
+	declarations := translationUnit.Functions[0].Body.Declarations
+	if len(declarations) != len(tests) {
+		t.Fatalf("declarations does not contain %d declarations. got=%d\n", len(tests), len(declarations))
+	}
+	for i, tt := range tests {
+		decl := declarations[i]
+		if decl.Name.Name() != tt.name {
+			t.Errorf("declarations[%d] - name is not %s. got=%s", i, tt.name, decl.Name.Name())
+		}
+		if decl.Type.String() != tt.typ {
+			t.Errorf("declarations[%d] - type is not %q. got=%q", i, tt.typ, decl.Type.String())
+		}
+	}
+}
+
+func TestInvalidArrayDeclaration(t *testing.T) {
+	tests := []string{
+		"int a[0];",
+		"int a[-1];",
+		"int n;\nint a[n];",
+		"int a[];",
+		"int a[2] = 1;",
+		"int a[2;",
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\n" + tt + "\nreturn 0;\n}"
+		expectPanic(t, i, input)
+	}
+}
+
+func TestPointerDeclaration(t *testing.T) {
+	input := `int *head = 0;
+	int *find(int *p, int **pp, int n) {
+		int *a[3], *q = p + n, x;
+		return *pp;
+	}
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	if translationUnit.Globals[0].String() != "int *head = 0;" {
+		t.Errorf("global is not %q. got=%q", "int *head = 0;", translationUnit.Globals[0].String())
+	}
+
+	function := translationUnit.Functions[0]
+	if function.Prototype.ReturnType.String() != "int *" {
+		t.Errorf("return type is not %q. got=%q", "int *", function.Prototype.ReturnType.String())
+	}
+
+	tests := []struct {
+		name string
+		typ  string
+	}{
+		{"p", "int *"},
+		{"pp", "int **"},
+		{"n", "int"},
+		{"a", "int *[3]"},
+		{"q", "int *"},
+		{"x", "int"},
+	}
+
+	declarations := function.Body.Declarations
+	if len(declarations) != len(tests) {
+		t.Fatalf("declarations does not contain %d declarations. got=%d\n", len(tests), len(declarations))
+	}
+	for i, tt := range tests {
+		decl := declarations[i]
+		if decl.Name.Name() != tt.name {
+			t.Errorf("declarations[%d] - name is not %s. got=%s", i, tt.name, decl.Name.Name())
+		}
+		if decl.Type.String() != tt.typ {
+			t.Errorf("declarations[%d] - type is not %q. got=%q", i, tt.typ, decl.Type.String())
+		}
+	}
+}
+
+func TestInvalidPointerUse(t *testing.T) {
+	tests := []string{
+		"int *g = 1;\nint main() {\nreturn 0;\n}",
+		"void *f();",
+		"int main() {\nint a;\nint *p = &1;\nreturn 0;\n}",
+		"int main() {\nint a;\nint *p = &(a + 1);\nreturn 0;\n}",
+		"int main() {\nint a;\n&a = 0;\nreturn 0;\n}",
+	}
+
+	for i, tt := range tests {
+		expectPanic(t, i, tt)
+	}
+}
+
+func TestCharDeclaration(t *testing.T) {
+	input := `char g = 'A';
+	char *name(char c, char *s) {
+		char buf[4], *p = s, **pp = &p;
+		switch (c) {
+		case 'a':
+			return "alpha" "bet";
+		case '\n':
+			return *pp;
+		}
+		return p;
+	}
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	if translationUnit.Globals[0].String() != "char g = 'A';" {
+		t.Errorf("global is not %q. got=%q", "char g = 'A';", translationUnit.Globals[0].String())
+	}
+	if translationUnit.Globals[0].Constant != 65 {
+		t.Errorf("global constant is not 65. got=%d", translationUnit.Globals[0].Constant)
+	}
+
+	function := translationUnit.Functions[0]
+	if function.Prototype.ReturnType.String() != "char *" {
+		t.Errorf("return type is not %q. got=%q", "char *", function.Prototype.ReturnType.String())
+	}
+
+	tests := []struct {
+		name string
+		typ  string
+	}{
+		{"c", "char"},
+		{"s", "char *"},
+		{"buf", "char [4]"},
+		{"p", "char *"},
+		{"pp", "char **"},
+	}
+
+	declarations := function.Body.Declarations
+	if len(declarations) != len(tests) {
+		t.Fatalf("declarations does not contain %d declarations. got=%d\n", len(tests), len(declarations))
+	}
+	for i, tt := range tests {
+		decl := declarations[i]
+		if decl.Name.Name() != tt.name {
+			t.Errorf("declarations[%d] - name is not %s. got=%s", i, tt.name, decl.Name.Name())
+		}
+		if decl.Type.String() != tt.typ {
+			t.Errorf("declarations[%d] - type is not %q. got=%q", i, tt.typ, decl.Type.String())
+		}
+	}
+
+	switchStmt, ok := function.Body.Statements[0].(*ast.SwitchStatement)
+	if !ok {
+		t.Fatalf("stmt is not SwitchStatement. got=%T", function.Body.Statements[0])
+	}
+	returnStmt := switchStmt.Cases[0].Statements[0].(*ast.ReturnStatement)
+	if returnStmt.String() != `return "alphabet";` {
+		t.Errorf("return is not %q. got=%q", `return "alphabet";`, returnStmt.String())
+	}
+}
+
+func TestInvalidVoidDeclaration(t *testing.T) {
+	tests := []string{
+		"void g;",
+		"int f(void x);",
+		"int main() {\nvoid v;\nreturn 0;\n}",
+	}
+
+	for i, tt := range tests {
+		expectPanic(t, i, tt)
+	}
+}
+
+func TestIntegerTypeSpecifiers(t *testing.T) {
+	input := `unsigned long long total;
+	unsigned short count(signed char c, unsigned char u, long int n) {
+		short s;
+		unsigned x;
+		signed y;
+		long unsigned int z;
+		int long long w;
+		return s;
+	}
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	if translationUnit.Globals[0].String() != "unsigned long long total;" {
+		t.Errorf("global is not %q. got=%q", "unsigned long long total;", translationUnit.Globals[0].String())
+	}
+
+	function := translationUnit.Functions[0]
+	if function.Prototype.ReturnType.String() != "unsigned short" {
+		t.Errorf("return type is not %q. got=%q", "unsigned short", function.Prototype.ReturnType.String())
+	}
+
+	tests := []struct {
+		name string
+		typ  string
+	}{
+		{"c", "char"},
+		{"u", "unsigned char"},
+		{"n", "long"},
+		{"s", "short"},
+		{"x", "unsigned int"},
+		{"y", "int"},
+		{"z", "unsigned long"},
+		{"w", "long long"},
+	}
+
+	declarations := function.Body.Declarations
+	if len(declarations) != len(tests) {
+		t.Fatalf("declarations does not contain %d declarations. got=%d\n", len(tests), len(declarations))
+	}
+	for i, tt := range tests {
+		decl := declarations[i]
+		if decl.Name.Name() != tt.name {
+			t.Errorf("declarations[%d] - name is not %s. got=%s", i, tt.name, decl.Name.Name())
+		}
+		if decl.Type.String() != tt.typ {
+			t.Errorf("declarations[%d] - type is not %q. got=%q", i, tt.typ, decl.Type.String())
+		}
+	}
+}
+
+func TestInvalidTypeSpecifier(t *testing.T) {
+	tests := []string{
+		"long long long g;",
+		"signed unsigned g;",
+		"short char g;",
+		"unsigned void f();",
+		"int int g;",
+		"short long g;",
+		"int main() {\nlong x = 10lul;\nreturn 0;\n}",
+		"int main() {\nlong x = 10uu;\nreturn 0;\n}",
+		"int main() {\nlong x = 10lL;\nreturn 0;\n}",
+	}
+
+	for i, tt := range tests {
+		expectPanic(t, i, tt)
+	}
+}
+
+func TestIntegerConstantTypes(t *testing.T) {
+	tests := []struct {
+		input string
+		typ   string
+	}{
+		{"1", "int"},
+		{"2147483647", "int"},
+		{"2147483648", "long"},
+		{"1u", "unsigned int"},
+		{"4294967295U", "unsigned int"},
+		{"4294967296u", "unsigned long"},
+		{"1l", "long"},
+		{"1UL", "unsigned long"},
+		{"1lu", "unsigned long"},
+		{"1LL", "long long"},
+		{"1ull", "unsigned long long"},
+		{"9223372036854775807", "long"},
+		{"9223372036854775808", "unsigned long"},
+		{"9223372036854775808LL", "unsigned long long"},
+		{"18446744073709551615ULL", "unsigned long long"},
+		{"'a'", "int"},
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\nreturn " + tt.input + ";\n}"
+
+		l := lexer.New(input)
+		p := New(l)
+		translationUnit := p.Parse()
+
+		returnStmt := translationUnit.Functions[0].Body.Statements[0].(*ast.ReturnStatement)
+		number, ok := returnStmt.ReturnValue.(*ast.Number)
+		if !ok {
+			t.Fatalf("tests[%d] - return value is not Number. got=%T", i, returnStmt.ReturnValue)
+		}
+		if number.Type.String() != tt.typ {
+			t.Errorf("tests[%d] - type of %s is not %q. got=%q", i, tt.input, tt.typ, number.Type.String())
+		}
+	}
+}
+
+func TestFloatingDeclaration(t *testing.T) {
+	input := `double scale(float f, double d) {
+		float x = 1.5f;
+		double y = 2.5;
+		return f * d;
+	}
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	function := translationUnit.Functions[0]
+	if function.Prototype.ReturnType != ast.Double {
+		t.Errorf("return type is not double. got=%q", function.Prototype.ReturnType.String())
+	}
+
+	tests := []struct {
+		name  string
+		typ   *ast.Type
+		value float64
+	}{
+		{"f", ast.Float, 0},
+		{"d", ast.Double, 0},
+		{"x", ast.Float, 1.5},
+		{"y", ast.Double, 2.5},
+	}
+
+	declarations := function.Body.Declarations
+	if len(declarations) != len(tests) {
+		t.Fatalf("declarations does not contain %d declarations. got=%d\n", len(tests), len(declarations))
+	}
+	for i, tt := range tests {
+		decl := declarations[i]
+		if decl.Name.Name() != tt.name {
+			t.Errorf("declarations[%d] - name is not %s. got=%s", i, tt.name, decl.Name.Name())
+		}
+		if decl.Type != tt.typ {
+			t.Errorf("declarations[%d] - type is not %q. got=%q", i, tt.typ.String(), decl.Type.String())
+		}
+		if decl.Value == nil {
+			continue
+		}
+		literal, ok := decl.Value.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("declarations[%d] - value is not FloatLiteral. got=%T", i, decl.Value)
+		}
+		if literal.Type != tt.typ || literal.Value != tt.value {
+			t.Errorf("declarations[%d] - literal is not %g of %q. got=%g of %q", i, tt.value, tt.typ.String(), literal.Value, literal.Type.String())
+		}
+	}
+}
+
+func TestFloatingConstantExpression(t *testing.T) {
+	tests := []struct {
+		input         string
+		constant      int
+		floatConstant float64
+	}{
+		{"double g = 1.5 * 2;", 0, 3},
+		{"double g = 1 / 2;", 0, 0},
+		{"double g = 1 / 2.0;", 0, 0.5},
+		{"float g = 0.1;", 0, float64(float32(0.1))},
+		{"double g = 0.1f;", 0, float64(float32(0.1))},
+		{"double g = -1e3;", 0, -1000},
+		{"double g = 4294967295u;", 0, 4294967295},
+		{"double g = 1 ? 3 : 0.5;", 0, 3},
+		{"int g = 2.9;", 2, 0},
+		{"int g = -2.9;", -2, 0},
+		{"unsigned char g = 255.0;", 255, 0},
+		{"int g = 1.5 < 2;", 1, 0},
+		{"int g = !0.0;", 1, 0},
+		{"int g = 0.5 && 1;", 1, 0},
+	}
+
+	for i, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		translationUnit := p.Parse()
+
+		global := translationUnit.Globals[0]
+		if global.Constant != tt.constant {
+			t.Errorf("tests[%d] - constant of %q is not %d. got=%d", i, tt.input, tt.constant, global.Constant)
+		}
+		if global.FloatConstant != tt.floatConstant {
+			t.Errorf("tests[%d] - float constant of %q is not %g. got=%g", i, tt.input, tt.floatConstant, global.FloatConstant)
+		}
+	}
+}
+
+func TestInvalidFloatingDeclaration(t *testing.T) {
+	tests := []string{
+		"long double g;",
+		"unsigned float g;",
+		"float double g;",
+		"short float g;",
+		"double g = 1.5 % 2;",
+		"double g = 1.0 << 2;",
+		"int g = ~1.0;",
+		"int *p = 0.0;",
+	}
+
+	for i, tt := range tests {
+		expectPanic(t, i, tt)
+	}
+}
+
+func TestStructDeclaration(t *testing.T) {
+	input := `struct point { int x, y; };
+	struct node { char tag; struct node *next; double value; int data[3]; } head;
+	int main() {
+		struct point p, *q;
+		struct rect { struct point min, max; } r;
+		q = &p;
+		q->x = 1;
+		r.min.y = p.x;
+		return head.next->tag;
+	}
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	if len(translationUnit.Globals) != 1 {
+		t.Fatalf("globals does not contain %d declarations. got=%d\n", 1, len(translationUnit.Globals))
+	}
+	node := translationUnit.Globals[0].Type
+	if node.String() != "struct node" {
+		t.Errorf("head is not struct node. got=%q", node.String())
+	}
+
+	members := []struct {
+		name string
+		typ  string
+	}{
+		{"tag", "char"},
+		{"next", "struct node *"},
+		{"value", "double"},
+		{"data", "int [3]"},
+	}
+
+	for i, tt := range members {
+		member := node.Member(tt.name)
+		if member == nil {
+			t.Fatalf("members[%d] - struct node has no member %s", i, tt.name)
+		}
+		if member.Type.String() != tt.typ {
+			t.Errorf("members[%d] - %s is not %q. got=%q", i, tt.name, tt.typ, member.Type.String())
+		}
+	}
+	if node.Member("next").Type.Elem != node {
+		t.Errorf("next does not point to struct node itself")
+	}
+
+	function := translationUnit.Functions[0]
+	declarations := []struct {
+		name string
+		typ  string
+	}{
+		{"p", "struct point"},
+		{"q", "struct point *"},
+		{"r", "struct rect"},
+	}
+
+	for i, tt := range declarations {
+		decl := function.Body.Declarations[i]
+		if decl.Name.Name() != tt.name || decl.Type.String() != tt.typ {
+			t.Errorf("declarations[%d] - %s is not %q. got=%s %q", i, tt.name, tt.typ, decl.Name.Name(), decl.Type.String())
+		}
+	}
+
+	statements := []string{
+		"(q = (&p))",
+		"((q->x) = 1)",
+		"(((r.min).y) = (p.x))",
+		"return ((head.next)->tag);",
+	}
+
+	for i, tt := range statements {
+		if function.Body.Statements[i].String() != tt {
+			t.Errorf("statements[%d] - is not %q. got=%q", i, tt, function.Body.Statements[i].String())
+		}
+	}
+}
+
+func TestStructTags(t *testing.T) {
+	input := `struct list;
+	struct list *first;
+	struct list { int value; struct list *rest; };
+	int main() {
+		struct list { char c; } inner;
+		{
+			struct list;
+			struct list *p;
+			struct list { double d; } deep;
+		}
+		return first->value;
+	}
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	list := translationUnit.Globals[0].Type.Elem
+	if !list.IsComplete() || list.Member("rest") == nil {
+		t.Errorf("struct list is not completed by the later definition")
+	}
+
+	declarations := translationUnit.Functions[0].Body.Declarations
+	inner := declarations[0].Type
+	if inner == list || inner.Member("c") == nil {
+		t.Errorf("inner struct list does not hide the outer one")
+	}
+
+	block := translationUnit.Functions[0].Body.Statements[0].(*ast.BlockStatement)
+	pointer := block.Statements[0].(*ast.DeclarationStatement).Type
+	deep := block.Statements[1].(*ast.DeclarationStatement).Type
+	if pointer.Elem != deep || deep == inner {
+		t.Errorf("struct list; does not declare a new struct in the block")
+	}
+}
+
+func TestInvalidStructDeclaration(t *testing.T) {
+	tests := []string{
+		"struct s { int x; int x; };",
+		"struct s { int x, y, x; };",
+		"struct s { };",
+		"struct s { int x; };\nstruct s { int y; };",
+		"struct s { struct s inner; };",
+		"struct s { void v; };",
+		"struct s { int x = 1; };",
+		"struct s;\nstruct s g;",
+		"struct t g;",
+		"struct;",
+		"struct *g;",
+		"unsigned struct s { int x; } g;",
+		"struct s { int x; };\nint f(struct s v);",
+		"struct s { int x; };\nstruct s f();",
+		"struct s { int x; };\nstruct s g = 5;",
+		"struct s { int x; } g = 0;",
+		"int main() {\nint x;\nx.;\nreturn 0;\n}",
+		"int main() {\nint x;\nx->1;\nreturn 0;\n}",
+		"struct s { int x; } g;\nint main() {\ng.x + 1 = 2;\nreturn 0;\n}",
+	}
+
+	for i, tt := range tests {
+		expectPanic(t, i, tt)
+	}
+}
+
+func TestUnionDeclaration(t *testing.T) {
+	input := `union number { char c; int i; double d; } n;
+	union bytes { char c[5]; int i; } b;
+	union small { char a; short b; } s;
+	struct tagged { int kind; union number value; } t;
+	int main() {
+		union number *p;
+		p = &t.value;
+		p->i = 1;
+		return n.c + t.value.i;
+	}
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	globals := []string{
+		"union number",
+		"union bytes",
+		"union small",
+		"struct tagged",
+	}
+
+	for i, tt := range globals {
+		if typ := translationUnit.Globals[i].Type; typ.String() != tt {
+			t.Errorf("globals[%d] - is not %q. got=%q", i, tt, typ.String())
+		}
+	}
+
+	members := []struct {
+		name string
+		typ  string
+	}{
+		{"c", "char"},
+		{"i", "int"},
+		{"d", "double"},
+	}
+
+	for i, tt := range members {
+		member := translationUnit.Globals[0].Type.Member(tt.name)
+		if member == nil || member.Type.String() != tt.typ {
+			t.Errorf("members[%d] - union number has no member %s of %q", i, tt.name, tt.typ)
+		}
+	}
+	if value := translationUnit.Globals[3].Type.Member("value"); value.Type != translationUnit.Globals[0].Type {
+		t.Errorf("value is not union number. got=%q", value.Type.String())
+	}
+
+	statements := []string{
+		"(p = (&(t.value)))",
+		"((p->i) = 1)",
+		"return ((n.c) + ((t.value).i));",
+	}
+
+	function := translationUnit.Functions[0]
+	for i, tt := range statements {
+		if function.Body.Statements[i].String() != tt {
+			t.Errorf("statements[%d] - is not %q. got=%q", i, tt, function.Body.Statements[i].String())
+		}
+	}
+}
+
+func TestInvalidUnionDeclaration(t *testing.T) {
+	tests := []string{
+		"union u { int x; char x; };",
+		"union u { };",
+		"union u { int x; };\nunion u { int y; };",
+		"union u { union u inner; };",
+		"struct s { int x; };\nunion s g;",
+		"union u { int x; };\nstruct u *g;",
+		"union u;\nunion u g;",
+		"union u { int x; };\nint f(union u v);",
+		"union u { int x; };\nunion u f();",
+		"struct union u { int x; } g;",
+		"union number { int i; double d; };\nunion number g = 7;",
+		"union u { int x; } g = 0;",
+	}
+
+	for i, tt := range tests {
+		expectPanic(t, i, tt)
+	}
+}
+
 func TestReturnStatement(t *testing.T) {
 	input := `int main() {
 		return 0;
@@ -113,3 +909,733 @@ func TestCallStatement(t *testing.T) {
 			stmt.Expression)
 	}
 }
+
+func TestIfStatement(t *testing.T) {
+	input := `int main() {
+		int i;
+		i = 3;
+		if (i < 5) {
+			i = i * 2;
+		} else if (i == 5)
+			i = 0;
+		return i;
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	statements := translationUnit.Functions[0].Body.Statements
+	if len(statements) != 3 {
+		t.Fatalf("statements does not contain %d statements. got=%d\n", 3, len(statements))
+	}
+
+	stmt, ok := statements[1].(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.IfStatement. got=%T", statements[1])
+	}
+	if stmt.Condition.String() != "(i < 5)" {
+		t.Fatalf("condition is not (i < 5). got=%s", stmt.Condition.String())
+	}
+	if _, ok := stmt.Consequence.(*ast.BlockStatement); !ok {
+		t.Fatalf("consequence is not ast.BlockStatement. got=%T", stmt.Consequence)
+	}
+
+	alt, ok := stmt.Alternative.(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("alternative is not ast.IfStatement. got=%T", stmt.Alternative)
+	}
+	if alt.Condition.String() != "(i == 5)" {
+		t.Fatalf("condition is not (i == 5). got=%s", alt.Condition.String())
+	}
+	if alt.Alternative != nil {
+		t.Fatalf("alternative should be nil. got=%T", alt.Alternative)
+	}
+}
+
+func TestWhileStatement(t *testing.T) {
+	input := `int main() {
+		int i;
+		i = 0;
+		while (i < 10) {
+			i = i + 1;
+			if (i == 3)
+				continue;
+			while (1)
+				break;
+		}
+		return i;
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	statements := translationUnit.Functions[0].Body.Statements
+	stmt, ok := statements[1].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.WhileStatement. got=%T", statements[1])
+	}
+	if stmt.Condition.String() != "(i < 10)" {
+		t.Fatalf("condition is not (i < 10). got=%s", stmt.Condition.String())
+	}
+
+	body, ok := stmt.Body.(*ast.BlockStatement)
+	if !ok {
+		t.Fatalf("body is not ast.BlockStatement. got=%T", stmt.Body)
+	}
+	if len(body.Statements) != 3 {
+		t.Fatalf("body does not contain %d statements. got=%d\n", 3, len(body.Statements))
+	}
+
+	ifStmt := body.Statements[1].(*ast.IfStatement)
+	if _, ok := ifStmt.Consequence.(*ast.ContinueStatement); !ok {
+		t.Fatalf("stmt is not ast.ContinueStatement. got=%T", ifStmt.Consequence)
+	}
+
+	inner := body.Statements[2].(*ast.WhileStatement)
+	if _, ok := inner.Body.(*ast.BreakStatement); !ok {
+		t.Fatalf("stmt is not ast.BreakStatement. got=%T", inner.Body)
+	}
+}
+
+func TestBreakOutsideLoop(t *testing.T) {
+	input := `int main() {
+		break;
+		return 0;
+	}`
+
+	if !rejects(input) {
+		t.Fatal("break outside of loop should be rejected")
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	input := `int main() {
+		int i;
+		int sum;
+		sum = 0;
+		for (i = 0; i < 10; i = i * 2) {
+			if (i == 3)
+				continue;
+			sum = sum * i;
+		}
+		for (;;)
+			break;
+		return sum;
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	statements := translationUnit.Functions[0].Body.Statements
+	if len(statements) != 4 {
+		t.Fatalf("statements does not contain %d statements. got=%d\n", 4, len(statements))
+	}
+
+	stmt, ok := statements[1].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ForStatement. got=%T", statements[1])
+	}
+	if stmt.Init.String() != "(i = 0)" {
+		t.Fatalf("init is not (i = 0). got=%s", stmt.Init.String())
+	}
+	if stmt.Condition.String() != "(i < 10)" {
+		t.Fatalf("condition is not (i < 10). got=%s", stmt.Condition.String())
+	}
+	if stmt.Step.String() != "(i = (i * 2))" {
+		t.Fatalf("step is not (i = (i * 2)). got=%s", stmt.Step.String())
+	}
+
+	empty, ok := statements[2].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ForStatement. got=%T", statements[2])
+	}
+	if empty.Init != nil || empty.Condition != nil || empty.Step != nil {
+		t.Fatalf("omitted clauses should be nil. got=%s", empty.String())
+	}
+	if _, ok := empty.Body.(*ast.BreakStatement); !ok {
+		t.Fatalf("body is not ast.BreakStatement. got=%T", empty.Body)
+	}
+}
+
+func TestForDeclaration(t *testing.T) {
+	input := `int main() {
+		int sum = 0;
+		for (int i = 0, j = 10; i < j; i++) {
+			int i = 1;
+			sum += i * j;
+		}
+		for (int i = 0; i < 3; i++)
+			sum += i;
+		return sum;
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	statements := translationUnit.Functions[0].Body.Statements
+	stmt, ok := statements[0].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ForStatement. got=%T", statements[0])
+	}
+	if stmt.Init != nil {
+		t.Fatalf("init should be nil. got=%s", stmt.Init.String())
+	}
+
+	declarations := []string{"int i = 0;", "int j = 10;"}
+	if len(stmt.Declarations) != len(declarations) {
+		t.Fatalf("init does not declare %d variables. got=%d", len(declarations), len(stmt.Declarations))
+	}
+	for i, tt := range declarations {
+		if stmt.Declarations[i].String() != tt {
+			t.Errorf("declarations[%d] - is not %q. got=%q", i, tt, stmt.Declarations[i].String())
+		}
+	}
+	if stmt.Condition.String() != "(i < j)" {
+		t.Fatalf("condition is not (i < j). got=%s", stmt.Condition.String())
+	}
+
+	// 2つ目のループのiは1つ目のループのiと別の変数
+	if _, ok := statements[1].(*ast.ForStatement); !ok {
+		t.Fatalf("stmt is not ast.ForStatement. got=%T", statements[1])
+	}
+}
+
+func TestInvalidForDeclaration(t *testing.T) {
+	tests := []string{
+		"for (int i = 0, i = 1; ; ) break;",
+		"for (int i = 0) break;",
+		"for (int i = 0; i < 3) break;",
+		"for (int a[2] = 0; ; ) break;",
+		"for (void v; ; ) break;",
+	}
+
+	for i, tt := range tests {
+		input := "int main() {\n" + tt + "\nreturn 0;\n}"
+		expectPanic(t, i, input)
+	}
+}
+
+func TestDoWhileStatement(t *testing.T) {
+	input := `int main() {
+		int i;
+		i = 0;
+		do {
+			i = i * 2;
+			if (i > 5)
+				break;
+		} while (i < 10);
+		do i = i * 3; while (i != 0);
+		return i;
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	statements := translationUnit.Functions[0].Body.Statements
+	if len(statements) != 4 {
+		t.Fatalf("statements does not contain %d statements. got=%d\n", 4, len(statements))
+	}
+
+	stmt, ok := statements[1].(*ast.DoWhileStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.DoWhileStatement. got=%T", statements[1])
+	}
+	if _, ok := stmt.Body.(*ast.BlockStatement); !ok {
+		t.Fatalf("body is not ast.BlockStatement. got=%T", stmt.Body)
+	}
+	if stmt.Condition.String() != "(i < 10)" {
+		t.Fatalf("condition is not (i < 10). got=%s", stmt.Condition.String())
+	}
+
+	single, ok := statements[2].(*ast.DoWhileStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.DoWhileStatement. got=%T", statements[2])
+	}
+	if single.Condition.String() != "(i != 0)" {
+		t.Fatalf("condition is not (i != 0). got=%s", single.Condition.String())
+	}
+}
+
+func TestSwitchStatement(t *testing.T) {
+	input := `int main() {
+		int i;
+		int j;
+		i = 2;
+		switch (i) {
+		case 1:
+			j = 10;
+			break;
+		case 1 + 1:
+		case 3:
+			j = 20;
+		default:
+			j = 0;
+		}
+		return j;
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	statements := translationUnit.Functions[0].Body.Statements
+	stmt, ok := statements[1].(*ast.SwitchStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.SwitchStatement. got=%T", statements[1])
+	}
+	if stmt.Tag.String() != "i" {
+		t.Fatalf("tag is not i. got=%s", stmt.Tag.String())
+	}
+
+	tests := []struct {
+		isDefault     bool
+		constant      int
+		numStatements int
+	}{
+		{false, 1, 2},
+		{false, 2, 0},
+		{false, 3, 1},
+		{true, 0, 1},
+	}
+
+	if len(stmt.Cases) != len(tests) {
+		t.Fatalf("switch does not contain %d clauses. got=%d", len(tests), len(stmt.Cases))
+	}
+	for i, tt := range tests {
+		clause := stmt.Cases[i]
+		if clause.IsDefault() != tt.isDefault {
+			t.Fatalf("cases[%d] - default wrong. expected=%t, got=%t", i, tt.isDefault, clause.IsDefault())
+		}
+		if !tt.isDefault && clause.Constant != tt.constant {
+			t.Fatalf("cases[%d] - constant wrong. expected=%d, got=%d", i, tt.constant, clause.Constant)
+		}
+		if len(clause.Statements) != tt.numStatements {
+			t.Fatalf("cases[%d] - statements wrong. expected=%d, got=%d", i, tt.numStatements, len(clause.Statements))
+		}
+	}
+}
+
+func TestSwitchDuplicateCase(t *testing.T) {
+	input := `int main() {
+		int i;
+		switch (i) {
+		case 2:
+			break;
+		case 1 * 2:
+			break;
+		}
+		return 0;
+	}`
+
+	if !rejects(input) {
+		t.Fatal("duplicate case value should be rejected")
+	}
+}
+
+func TestGotoStatement(t *testing.T) {
+	input := `int main() {
+		int i;
+		i = 0;
+	loop:
+		i = i * 2;
+		if (i < 10)
+			goto loop;
+		goto end;
+	end:
+		i = i * 3;
+		return i;
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	statements := translationUnit.Functions[0].Body.Statements
+	if len(statements) != 6 {
+		t.Fatalf("statements does not contain %d statements. got=%d\n", 6, len(statements))
+	}
+
+	labeled, ok := statements[1].(*ast.LabeledStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.LabeledStatement. got=%T", statements[1])
+	}
+	if labeled.Label.Name() != "loop" {
+		t.Fatalf("label is not loop. got=%s", labeled.Label.Name())
+	}
+	if _, ok := labeled.Statement.(*ast.ExpressionStatement); !ok {
+		t.Fatalf("labeled statement is not ast.ExpressionStatement. got=%T", labeled.Statement)
+	}
+
+	gotoStmt, ok := statements[3].(*ast.GotoStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.GotoStatement. got=%T", statements[3])
+	}
+	if gotoStmt.Label.Name() != "end" {
+		t.Fatalf("label is not end. got=%s", gotoStmt.Label.Name())
+	}
+
+	if _, ok := statements[4].(*ast.LabeledStatement); !ok {
+		t.Fatalf("stmt is not ast.LabeledStatement. got=%T", statements[4])
+	}
+}
+
+func TestInvalidLabels(t *testing.T) {
+	tests := []string{
+		`int main() {
+			goto missing;
+			return 0;
+		}`,
+		`int main() {
+			int i;
+		twice:
+			i = 1;
+		twice:
+			i = 2;
+			return i;
+		}`,
+	}
+
+	for i, input := range tests {
+		expectPanic(t, i, input)
+	}
+}
+
+func TestOperatorPrecedence(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a || b && c", "(a || (b && c))"},
+		{"a && b || c", "((a && b) || c)"},
+		{"a == b && c < d", "((a == b) && (c < d))"},
+		{"!a == b", "((!a) == b)"},
+		{"!a && !!b", "((!a) && (!(!b)))"},
+		{"a + b * c < d || e", "(((a + (b * c)) < d) || e)"},
+		{"-a * b", "((-a) * b)"},
+		{"a - -b", "(a - (-b))"},
+		{"+a + ~b", "((+a) + (~b))"},
+		{"~-!a", "(~(-(!a)))"},
+		{"-f(a) + b", "((-f(a)) + b)"},
+		{"a * b % c", "((a * b) % c)"},
+		{"a + b % c", "(a + (b % c))"},
+		{"a << b + c", "(a << (b + c))"},
+		{"a[i + 1] * b", "((a[(i + 1)]) * b)"},
+		{"*p++", "(*(p++))"},
+		{"a * *p", "(a * (*p))"},
+		{"*p + 1", "((*p) + 1)"},
+		{"&a[1]", "(&(a[1]))"},
+		{"**q = a & b", "((*(*q)) = (a & b))"},
+		{"-a[i]++", "(-((a[i])++))"},
+		{"a[b[i]] = c[0][1]", "((a[(b[i])]) = ((c[0])[1]))"},
+		{"a < b << c", "(a < (b << c))"},
+		{"a >> b >> c", "((a >> b) >> c)"},
+		{"a & b == c", "(a & (b == c))"},
+		{"a | b ^ c & d", "(a | (b ^ (c & d)))"},
+		{"a && b | c", "(a && (b | c))"},
+		{"a++", "(a++)"},
+		{"-a--", "(-(a--))"},
+		{"++a * 2", "((++a) * 2)"},
+		{"--a + b++", "((--a) + (b++))"},
+		{"a += b * 2", "(a += (b * 2))"},
+		{"a %= 3", "(a %= 3)"},
+		{"a ? b : c", "(a ? b : c)"},
+		{"a || b ? c + 1 : d * 2", "((a || b) ? (c + 1) : (d * 2))"},
+		{"a ? b : c ? d : e", "(a ? b : (c ? d : e))"},
+		{"a ? b ? c : d : e", "(a ? (b ? c : d) : e)"},
+		{"(a + b) * c", "(((a + b)) * c)"},
+		{"a * (b + c)", "(a * ((b + c)))"},
+		{"-(a - b)", "(-((a - b)))"},
+		{"((a))", "((a))"},
+		{"f((a + b) / 2)", "f((((a + b)) / 2))"},
+		{"!(a && b) || c", "((!((a && b))) || c)"},
+		{"a = b + c", "(a = (b + c))"},
+		{"a = b = c + 1", "(a = (b = (c + 1)))"},
+		{"a += b -= 2", "(a += (b -= 2))"},
+		{"a = b < c", "(a = (b < c))"},
+		{"a = b ? c : d", "(a = (b ? c : d))"},
+		{"a ? b = 1 : c", "(a ? (b = 1) : c)"},
+		{"(a) = 1", "((a) = 1)"},
+		{"f(a = 3) + 1", "(f((a = 3)) + 1)"},
+	}
+
+	for i, tt := range tests {
+		input := "int f(int x);\nint main() {\n" + tt.input + ";\nreturn 0;\n}"
+
+		l := lexer.New(input)
+		p := New(l)
+		translationUnit := p.Parse()
+
+		stmt := translationUnit.Functions[0].Body.Statements[0].(*ast.ExpressionStatement)
+		if stmt.Expression.String() != tt.expected {
+			t.Errorf("tests[%d] - expected=%q, got=%q", i, tt.expected, stmt.Expression.String())
+		}
+	}
+}
+
+func TestVoidFunction(t *testing.T) {
+	input := `void reset(void);
+	void count(int n) {
+		if (n < 0)
+			return;
+		printnum(n);
+	}
+	int main() {
+		count(3);
+	}
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	prototype := translationUnit.Prototypes[len(translationUnit.Prototypes)-1]
+	if !prototype.IsVoid() || prototype.GetParamNum() != 0 {
+		t.Fatalf("prototype is not void reset(void). got=%s", prototype.String())
+	}
+
+	function := translationUnit.Functions[0]
+	if !function.Prototype.IsVoid() {
+		t.Fatalf("function %s is not void", function.GetName())
+	}
+	ifStmt := function.Body.Statements[0].(*ast.IfStatement)
+	returnStmt, ok := ifStmt.Consequence.(*ast.ReturnStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ReturnStatement. got=%T", ifStmt.Consequence)
+	}
+	if returnStmt.ReturnValue != nil {
+		t.Fatalf("return value is not nil. got=%s", returnStmt.ReturnValue.String())
+	}
+}
+
+func TestControlReachesEnd(t *testing.T) {
+	tests := []struct {
+		body     string
+		rejected bool
+	}{
+		{"return x;", false},
+		{"x = 1;", true},
+		{"if (x) return 1;", true},
+		{"if (x) return 1; else return 2;", false},
+		{"if (x) return 1; else x = 2;", true},
+		{"{ return x; }", false},
+		{"while (1) x++;", false},
+		{"while (1) { if (x) break; }", true},
+		{"while (x) return 1;", true},
+		{"for (;;) { x++; }", false},
+		{"for (;;) { switch (x) { case 1: break; } }", false},
+		{"do { x++; } while (1);", false},
+		{"do { return 1; } while (x);", false},
+		{"do { if (x) continue; return 1; } while (x);", true},
+		{"switch (x) { case 1: return 1; default: return 2; }", false},
+		{"switch (x) { case 1: return 1; }", true},
+		{"switch (x) { case 1: break; default: return 2; }", true},
+		{"return 1; end: x++;", true},
+		{"goto end; end: return x;", false},
+		{"goto end; return 1; { end: x++; }", true},
+		{"goto end; return 1; { end: return x; }", false},
+		{"goto end; return 1; if (x) { end: x++; }", true},
+		{"goto end; return 1; while (x) { end: x++; }", true},
+	}
+
+	for i, tt := range tests {
+		input := "int f(int x) {\n" + tt.body + "\n}\nint main() {\nreturn 0;\n}"
+		if rejected := rejects(input); rejected != tt.rejected {
+			t.Fatalf("tests[%d] - %q rejected=%t, want %t", i, tt.body, rejected, tt.rejected)
+		}
+	}
+}
+
+func TestInvalidReturn(t *testing.T) {
+	tests := []string{
+		"void f() {\nreturn 1;\n}",
+		"int f() {\nreturn;\n}",
+		"void f();\nint f() {\nreturn 1;\n}",
+		"int f();\nvoid f() {\n}",
+		"void x;",
+	}
+
+	for i, tt := range tests {
+		expectPanic(t, i, tt)
+	}
+}
+
+func TestConflictingTypes(t *testing.T) {
+	tests := []struct {
+		input    string
+		rejected bool
+	}{
+		{"int f(int x);\nint f(int y) {\nreturn y;\n}", false},
+		{"int f(char *s);\nint f(char s[]) {\nreturn 0;\n}", false},
+		{"struct s { int x; };\nint f(struct s *p);\nint f(struct s *q) {\nreturn 0;\n}", false},
+		{"int f(int x);\nunsigned f(int x) {\nreturn x;\n}", true},
+		{"int f(int x);\nint f(long x) {\nreturn 0;\n}", true},
+		{"int f(char *s);\nint f(unsigned char *s) {\nreturn 0;\n}", true},
+		{"int f(int **p);\nint f(int *p) {\nreturn 0;\n}", true},
+		{"struct s { int x; };\nstruct t { int x; };\nint f(struct s *p);\nint f(struct t *p) {\nreturn 0;\n}", true},
+		{"int f(double d) {\nreturn 0;\n}\nint f(float d);", true},
+		{"double printnum(int i);", true},
+	}
+
+	for i, tt := range tests {
+		if rejected := rejects(tt.input); rejected != tt.rejected {
+			t.Fatalf("tests[%d] - %q rejected=%t, want %t", i, tt.input, rejected, tt.rejected)
+		}
+	}
+}
+
+func TestUnexpectedTokenInExpression(t *testing.T) {
+	input := `int main() {
+		return / 2;
+	}`
+
+	if !rejects(input) {
+		t.Fatal("expression starting with / should be rejected")
+	}
+}
+
+func TestGroupedExpression(t *testing.T) {
+	input := `int f(int x, int y);
+
+	int main() {
+		int a;
+		a = 2;
+		f((a + 1) * 2, (a));
+		return (a - 1) * (a + 1);
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	statements := translationUnit.Functions[0].Body.Statements
+
+	call := statements[1].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	if len(call.Arguments) != 2 {
+		t.Fatalf("call does not contain %d arguments. got=%d", 2, len(call.Arguments))
+	}
+	if call.Arguments[0].String() != "(((a + 1)) * 2)" {
+		t.Fatalf("argument is not (((a + 1)) * 2). got=%s", call.Arguments[0].String())
+	}
+	if _, ok := call.Arguments[1].(*ast.GroupedExpression); !ok {
+		t.Fatalf("argument is not ast.GroupedExpression. got=%T", call.Arguments[1])
+	}
+
+	ret := statements[2].(*ast.ReturnStatement)
+	if ret.ReturnValue.String() != "(((a - 1)) * ((a + 1)))" {
+		t.Fatalf("return value is not (((a - 1)) * ((a + 1))). got=%s", ret.ReturnValue.String())
+	}
+}
+
+func TestAssignToNonLvalue(t *testing.T) {
+	tests := []string{
+		"a + 1 = 2;",
+		"3 = a;",
+		"f(a) = 1;",
+		"a ? b : c = 1;",
+		"(a + b) += 1;",
+		"++1;",
+		"f(a)--;",
+	}
+
+	for i, tt := range tests {
+		input := "int f(int x);\nint main() {\nint a;\n" + tt + "\nreturn 0;\n}"
+		expectPanic(t, i, input)
+	}
+}
+
+func TestBlockScopes(t *testing.T) {
+	input := `int main() {
+		int a;
+		a = 1;
+		int b;
+		{
+			int a;
+			a = 2;
+			b = a;
+		}
+		return a + b;
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	translationUnit := p.Parse()
+
+	body := translationUnit.Functions[0].Body
+	if len(body.Declarations) != 1 {
+		t.Fatalf("declarations does not contain %d declarations. got=%d\n", 1, len(body.Declarations))
+	}
+
+	statements := body.Statements
+	if len(statements) != 4 {
+		t.Fatalf("statements does not contain %d statements. got=%d\n", 4, len(statements))
+	}
+
+	decl, ok := statements[1].(*ast.DeclarationStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.DeclarationStatement. got=%T", statements[1])
+	}
+	if decl.Name.Name() != "b" {
+		t.Fatalf("declared name is not b. got=%s", decl.Name.Name())
+	}
+
+	block, ok := statements[2].(*ast.BlockStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.BlockStatement. got=%T", statements[2])
+	}
+	if _, ok := block.Statements[0].(*ast.DeclarationStatement); !ok {
+		t.Fatalf("block stmt is not ast.DeclarationStatement. got=%T", block.Statements[0])
+	}
+}
+
+func TestRedeclaration(t *testing.T) {
+	tests := []struct {
+		input    string
+		rejected bool
+	}{
+		{"int a;\nint a;", true},
+		{"int a;\na = 1;\nint a;", true},
+		{"int a;\n{ int a; }", false},
+		{"{ int a; }\nint a;", false},
+		{"{ int b; int b; }", true},
+		{"int x;", true},
+		{"{ int x; }", false},
+		{"switch (1) { case 1: int a; default: int a; }", true},
+		{"int a = 1, a = 2;", true},
+		{"int a = 1, b = a;", false},
+		{"int a b;", true},
+		{"int a = ;", true},
+		{"int g;", false},
+	}
+
+	for i, tt := range tests {
+		input := "int g;\nint main(int x) {\n" + tt.input + "\nreturn 0;\n}"
+		if rejected := rejects(input); rejected != tt.rejected {
+			t.Fatalf("tests[%d] - %q rejected=%t, want %t", i, tt.input, rejected, tt.rejected)
+		}
+	}
+}
+
+// rejects - srcの解析がpanicするか
+func rejects(src string) (rejected bool) {
+	defer func() {
+		rejected = recover() != nil
+	}()
+
+	l := lexer.New(src)
+	p := New(l)
+	p.Parse()
+	return false
+}
+
+// expectPanic - tests[i]のsrcの解析がpanicしなければ失敗にする
+func expectPanic(t *testing.T, i int, src string) {
+	t.Helper()
+	if !rejects(src) {
+		t.Fatalf("tests[%d] - %q should be rejected", i, src)
+	}
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"../ast"
+	"fmt"
+)
+
+// scope - ブロックごとの変数表 内側のスコープの変数は外側の同名の変数を隠す
+// 構造体と共用体のタグは変数とは別の1つの名前空間で、同じように内側のものが外側のものを隠す
+type scope struct {
+	outer     *scope               // 1つ外側のスコープ 関数のスコープではnil
+	variables []string             // このスコープで宣言された変数名
+	tags      map[string]*ast.Type // このスコープで宣言された構造体と共用体の型
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{outer: outer, tags: map[string]*ast.Type{}}
+}
+
+// declare - このスコープに変数を登録する 同じスコープで宣言済みならfalseを返す
+func (s *scope) declare(name string) bool {
+	if contains(s.variables, name) {
+		return false
+	}
+	s.variables = append(s.variables, name)
+	return true
+}
+
+func (p *Parser) openScope() {
+	p.scope = newScope(p.scope)
+}
+
+func (p *Parser) closeScope() {
+	p.scope = p.scope.outer
+}
+
+// declareVariable - 現在のスコープに変数を登録する
+func (p *Parser) declareVariable(name string) {
+	if !p.scope.declare(name) {
+		msg := fmt.Sprintf("%s is already declared in this scope", name)
+		panic(msg)
+	}
+}
+
+// lookupTag - 内側のスコープから順に構造体と共用体のタグを探す 見つからなければnil
+func (s *scope) lookupTag(tag string) *ast.Type {
+	for ; s != nil; s = s.outer {
+		if t, ok := s.tags[tag]; ok {
+			return t
+		}
+	}
+	return nil
+}
+
+// declareTag - 現在のスコープの構造体か共用体の型 まだなければ不完全型として登録する
+func (p *Parser) declareTag(kind ast.TypeKind, tag string) *ast.Type {
+	if t, ok := p.scope.tags[tag]; ok {
+		checkTagKind(t, kind)
+		return t
+	}
+	t := newTaggedType(kind, tag)
+	p.scope.tags[tag] = t
+	return t
+}
+
+// checkTagKind - struct タグで宣言したタグをunion タグとして使うとpanicする
+func checkTagKind(t *ast.Type, kind ast.TypeKind) {
+	if t.Kind != kind {
+		msg := fmt.Sprintf("%s is already declared as %s", t.Tag, t.Keyword())
+		panic(msg)
+	}
+}
+
+func newTaggedType(kind ast.TypeKind, tag string) *ast.Type {
+	if kind == ast.UnionType {
+		return ast.UnionOf(tag)
+	}
+	return ast.StructOf(tag)
+}
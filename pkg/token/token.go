@@ -1,6 +1,10 @@
 package token
 
-import "strconv"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 type TokenType string
 
@@ -12,12 +16,44 @@ const (
 	IDENT = "IDENT" // add, foobar, x, y, ...
 	DIGIT = "DIGIT" // 1343456
 
+	FLOATING  = "FLOATING"  // 1.5, 2e10
+	CHARACTER = "CHARACTER" // 'a', '\n'
+	STRING    = "STRING"    // "hello\n"
+
 	// Operators
 	ASSIGN   = "="
 	PLUS     = "+"
 	MINUS    = "-"
 	ASTERISK = "*"
 	SLASH    = "/"
+	PERCENT  = "%"
+
+	AMPERSAND = "&"
+	PIPE      = "|"
+	CARET     = "^"
+	LSHIFT    = "<<"
+	RSHIFT    = ">>"
+
+	LT     = "<"
+	GT     = ">"
+	LE     = "<="
+	GE     = ">="
+	EQ     = "=="
+	NOT_EQ = "!="
+
+	AND  = "&&"
+	OR   = "||"
+	BANG = "!"
+
+	TILDE = "~"
+
+	INCREMENT       = "++"
+	DECREMENT       = "--"
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+	PERCENT_ASSIGN  = "%="
 
 	// Delimiters
 	COMMA     = ","
@@ -30,33 +66,67 @@ const (
 	LBRACKET = "["
 	RBRACKET = "]"
 	COLON    = ":"
+	QUESTION = "?"
+	DOT      = "."
+	ARROW    = "->"
 
 	// Keywords
-	INTTYPE = "INT"
-	RETURN  = "RETURN"
+	INTTYPE    = "INT"
+	CHARTYPE   = "CHAR"
+	FLOATTYPE  = "FLOAT"
+	DOUBLETYPE = "DOUBLE"
+	STRUCT     = "STRUCT"
+	UNION      = "UNION"
+	SHORTTYPE  = "SHORT"
+	LONGTYPE   = "LONG"
+	SIGNED     = "SIGNED"
+	UNSIGNED   = "UNSIGNED"
+	VOIDTYPE   = "VOID"
+	RETURN     = "RETURN"
+	IF         = "IF"
+	ELSE       = "ELSE"
+	WHILE      = "WHILE"
+	FOR        = "FOR"
+	DO         = "DO"
+	SWITCH     = "SWITCH"
+	CASE       = "CASE"
+	DEFAULT    = "DEFAULT"
+	BREAK      = "BREAK"
+	CONTINUE   = "CONTINUE"
+	GOTO       = "GOTO"
 )
 
 type Token struct {
 	Type    TokenType
 	Literal string
 	Number  int
+	Float   float64
 	Line    int
 }
 
 func New(tokenType TokenType, literal string, line int) *Token {
 	number := 0x7fffffff
-	var err error
 	if tokenType == DIGIT {
-		number, err = strconv.Atoi(literal)
+		// 10ULのような接尾辞は値に含めない
+		// unsigned long long まで読めるので、int64に収まらない値はビットパターンのまま持つ
+		value, err := strconv.ParseUint(strings.TrimRight(literal, "uUlL"), 10, 64)
 		if err != nil {
-			number = 0x7fffffff
+			panic(fmt.Sprintf("line %d: integer constant %s is too large for its type", line, literal))
 		}
+		number = int(value)
+	}
+
+	// 1.5fのような接尾辞は値に含めない
+	var float float64
+	if tokenType == FLOATING {
+		float, _ = strconv.ParseFloat(strings.TrimRight(literal, "fF"), 64)
 	}
 
 	return &Token{
 		Type:    tokenType,
 		Literal: literal,
 		Number:  number,
+		Float:   float,
 		Line:    line,
 	}
 }
@@ -3,3 +3,7 @@
 int printnum(int i) {
     return printf("%d\n", i);
 }
+
+int printdouble(double d) {
+    return printf("%f\n", d);
+}
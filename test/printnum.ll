@@ -4,6 +4,7 @@ target datalayout = "e-m:e-i64:64-f80:128-n8:16:32:64-S128"
 target triple = "x86_64-pc-linux-gnu"
 
 @.str = private unnamed_addr constant [4 x i8] c"%d\0A\00", align 1
+@.str.1 = private unnamed_addr constant [4 x i8] c"%f\0A\00", align 1
 
 ; Function Attrs: nofree nounwind uwtable
 define dso_local i32 @printnum(i32) local_unnamed_addr #0 {
@@ -11,6 +12,12 @@ define dso_local i32 @printnum(i32) local_unnamed_addr #0 {
   ret i32 %2
 }
 
+; Function Attrs: nofree nounwind uwtable
+define dso_local i32 @printdouble(double) local_unnamed_addr #0 {
+  %2 = tail call i32 (i8*, ...) @printf(i8* getelementptr inbounds ([4 x i8], [4 x i8]* @.str.1, i64 0, i64 0), double %0)
+  ret i32 %2
+}
+
 ; Function Attrs: nofree nounwind
 declare dso_local i32 @printf(i8* nocapture readonly, ...) local_unnamed_addr #1
 